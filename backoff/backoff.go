@@ -18,32 +18,50 @@ type Backoff struct {
 	Current    time.Duration
 	Multiplier float64
 	RandFactor float64
+	Jitter     Jitter
+
+	// Hooks are called by the retry loops built on the backoff, such
+	// as Retry and pinger.Pinger.
+	Hooks Hooks
+
+	// rand is the source of randomness for Jitter, the global
+	// source of math/rand/v2 is used when it is nil.
+	rand *rand.Rand
+
+	// prev is the delay returned by the previous call to Next,
+	// used by DecorrelatedJitter.
+	prev time.Duration
+
+	// started reports whether Next has been called since the
+	// backoff was created or reset, the first delay is always
+	// based on Interval.
+	started bool
 }
 
-func New() *Backoff {
+func New(opts ...Option) *Backoff {
 	backoff := &Backoff{
 		Interval:   DefaultInterval,
 		Max:        DefaultMaxInterval,
 		Current:    0,
 		Multiplier: DefaultMultiplier,
 		RandFactor: DefaultRandFactor,
+		Jitter:     RandFactorJitter,
+		rand:       nil,
+	}
+
+	for _, opt := range opts {
+		opt(backoff)
 	}
 
 	return backoff
 }
 
-func (b *Backoff) randTime(random float64) time.Duration {
-	if b.RandFactor == 0 {
-		return b.Current
+func (b *Backoff) random() float64 {
+	if b.rand == nil {
+		return rand.Float64()
 	}
 
-	current := float64(b.Current)
-	delta := b.RandFactor * float64(b.Current)
-
-	min := current - delta
-	max := current + delta
-
-	return time.Duration(max + (random * (max - min + 1)))
+	return b.rand.Float64()
 }
 
 func (b *Backoff) incrementCurrent() {
@@ -53,20 +71,38 @@ func (b *Backoff) incrementCurrent() {
 		return
 	}
 
-	b.Interval = time.Duration(current * b.Multiplier)
+	b.Current = time.Duration(current * b.Multiplier)
 }
 
-func (b *Backoff) Next() time.Duration {
-	if b.Current == 0 {
+// Next returns the delay before the next attempt. The delay grows
+// by Multiplier after every call and is never greater than Max, a
+// Backoff is never exhausted so the second value is always true.
+func (b *Backoff) Next() (time.Duration, bool) {
+	if !b.started || b.Current == 0 {
 		b.Current = b.Interval
+		b.started = true
+	} else {
+		b.incrementCurrent()
+	}
+
+	jitter := b.Jitter
+	if jitter == nil {
+		jitter = RandFactorJitter
+	}
+
+	next := jitter(b, b.prev, b.random())
+	next = max(next, 0)
+	if b.Max > 0 {
+		next = min(next, b.Max)
 	}
 
-	next := b.randTime(rand.Float64())
-	b.incrementCurrent()
+	b.prev = next
 
-	return next
+	return next, true
 }
 
 func (b *Backoff) Reset() {
 	b.Current = b.Interval
+	b.started = false
+	b.prev = 0
 }
@@ -1,6 +1,7 @@
 package backoff
 
 import (
+	"math/rand/v2"
 	"testing"
 	"time"
 
@@ -34,7 +35,7 @@ func (s *testBackoff) TestMaxOverflow() {
 }
 
 func (s *testBackoff) TestNext() {
-	next := s.backoff.Next()
+	next, _ := s.backoff.Next()
 
 	errMsg := "next must return 'current' value when 'rand factor' == 0"
 	s.Require().Equal(s.backoff.Current, next, errMsg)
@@ -47,3 +48,62 @@ func (s *testBackoff) TestReset() {
 	errMsg := "reset the 'current' value to 'initial interval'"
 	s.Require().Equal(s.backoff.Interval, s.backoff.Current, errMsg)
 }
+
+func (s *testBackoff) TestJitterBounds() {
+	jitters := map[string]Jitter{
+		"none":         NoJitter,
+		"rand factor":  RandFactorJitter,
+		"full":         FullJitter,
+		"equal":        EqualJitter,
+		"decorrelated": DecorrelatedJitter,
+	}
+
+	for name, jitter := range jitters {
+		s.backoff.Reset()
+		s.backoff.RandFactor = DefaultRandFactor
+		s.backoff.Jitter = jitter
+
+		for range 100 {
+			next, _ := s.backoff.Next()
+
+			errMsg := "%s jitter: next must be in range [0, max], got %v"
+			s.Require().GreaterOrEqualf(next, time.Duration(0), errMsg, name, next)
+			s.Require().LessOrEqualf(next, s.backoff.Max, errMsg, name, next)
+		}
+	}
+}
+
+func (s *testBackoff) TestJitterRange() {
+	s.backoff.Current = 4 * time.Second
+
+	full := FullJitter(s.backoff, 0, 0.5)
+	errMsg := "full jitter must scale 'current' by the random number"
+	s.Require().Equal(2*time.Second, full, errMsg)
+
+	equal := EqualJitter(s.backoff, 0, 0)
+	errMsg = "equal jitter must keep half of 'current'"
+	s.Require().Equal(2*time.Second, equal, errMsg)
+
+	decorrelated := DecorrelatedJitter(s.backoff, time.Second, 1)
+	errMsg = "decorrelated jitter must be bounded by 3 * 'prev'"
+	s.Require().Equal(3*time.Second, decorrelated, errMsg)
+
+	s.backoff.RandFactor = 0.5
+	randFactor := RandFactorJitter(s.backoff, 0, 0.99)
+	errMsg = "rand factor jitter must not exceed 'current' + delta"
+	s.Require().Less(randFactor, 6*time.Second, errMsg)
+}
+
+func (s *testBackoff) TestRandSource() {
+	seed1, seed2 := uint64(1), uint64(2)
+	backoff := New(WithRandSource(rand.NewPCG(seed1, seed2)))
+	random := rand.New(rand.NewPCG(seed1, seed2))
+
+	for range 5 {
+		next, _ := backoff.Next()
+		expected := RandFactorJitter(backoff, 0, random.Float64())
+
+		errMsg := "next must be reproducible with a custom rand source"
+		s.Require().Equal(expected, next, errMsg)
+	}
+}
@@ -0,0 +1,165 @@
+// Package backofftest provides utilities for testing code built on
+// the backoff package without real sleeps.
+package backofftest
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/mtchuikov/pkg/backoff"
+)
+
+var _ backoff.Clock = (*Clock)(nil)
+
+// Clock is a fake backoff.Clock whose time only moves when Advance
+// is called. Timers and After channels fire once the clock has been
+// advanced past their deadline. It is safe for concurrent use.
+type Clock struct {
+	mu     sync.Mutex
+	cond   *sync.Cond
+	now    time.Time
+	timers []*timer
+}
+
+// NewClock returns a fake clock set to now.
+func NewClock(now time.Time) *Clock {
+	c := &Clock{
+		mu:     sync.Mutex{},
+		now:    now,
+		timers: make([]*timer, 0, 3),
+	}
+
+	c.cond = sync.NewCond(&c.mu)
+
+	return c
+}
+
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+func (c *Clock) After(d time.Duration) <-chan time.Time {
+	return c.NewTimer(d).C()
+}
+
+func (c *Clock) NewTimer(d time.Duration) backoff.Timer {
+	t := &timer{
+		clock: c,
+		c:     make(chan time.Time, 1),
+	}
+
+	c.mu.Lock()
+	c.schedule(t, d)
+	c.mu.Unlock()
+
+	return t
+}
+
+// Advance moves the clock forward by d and fires every timer whose
+// deadline has been reached, in deadline order.
+func (c *Clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+
+	sort.SliceStable(c.timers, func(i, j int) bool {
+		return c.timers[i].deadline.Before(c.timers[j].deadline)
+	})
+
+	fired := 0
+	for _, t := range c.timers {
+		if t.deadline.After(c.now) {
+			break
+		}
+
+		select {
+		case t.c <- c.now:
+		default:
+		}
+
+		fired++
+	}
+
+	c.timers = c.timers[fired:]
+	c.cond.Broadcast()
+}
+
+// Timers returns the number of timers waiting to fire.
+func (c *Clock) Timers() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return len(c.timers)
+}
+
+// BlockUntil blocks until at least n timers are waiting to fire. It
+// is used to make sure that the code under test has started waiting
+// before the clock is advanced.
+func (c *Clock) BlockUntil(n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for len(c.timers) < n {
+		c.cond.Wait()
+	}
+}
+
+func (c *Clock) schedule(t *timer, d time.Duration) {
+	t.deadline = c.now.Add(d)
+
+	if d <= 0 {
+		select {
+		case t.c <- c.now:
+		default:
+		}
+
+		return
+	}
+
+	c.timers = append(c.timers, t)
+	c.cond.Broadcast()
+}
+
+func (c *Clock) remove(t *timer) bool {
+	for idx, i := range c.timers {
+		if i == t {
+			c.timers = append(c.timers[:idx], c.timers[idx+1:]...)
+			c.cond.Broadcast()
+			return true
+		}
+	}
+
+	return false
+}
+
+type timer struct {
+	clock    *Clock
+	c        chan time.Time
+	deadline time.Time
+}
+
+func (t *timer) C() <-chan time.Time {
+	return t.c
+}
+
+func (t *timer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+
+	return t.clock.remove(t)
+}
+
+func (t *timer) Reset(d time.Duration) bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+
+	active := t.clock.remove(t)
+	t.clock.schedule(t, d)
+
+	return active
+}
@@ -0,0 +1,110 @@
+package backoff
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultBudgetRatio = 0.1
+	DefaultBudgetRate  = 1
+)
+
+var ErrBudgetExhausted = errors.New("retry budget exhausted")
+
+// Budget is a token bucket shared by many retriers to keep them from
+// multiplying the load on a struggling dependency. Every retry
+// withdraws one token, every success deposits a fraction of a token
+// and the bucket also refills slowly over time, so that retrying
+// resumes eventually even if nothing succeeds. The bucket starts
+// full. It is safe for concurrent use.
+type Budget struct {
+	mu sync.Mutex
+
+	max    float64
+	ratio  float64
+	rate   float64
+	tokens float64
+
+	clock Clock
+	last  time.Time
+}
+
+// NewBudget returns a budget that holds at most max tokens and
+// deposits ratio tokens on every success, a ratio that is not
+// positive falls back to DefaultBudgetRatio. With the default ratio
+// of 0.1 retries can make up at most about 10% of the successful
+// calls once the initial tokens are spent.
+func NewBudget(max int, ratio float64, opts ...BudgetOption) *Budget {
+	if ratio <= 0 {
+		ratio = DefaultBudgetRatio
+	}
+
+	b := &Budget{
+		mu:     sync.Mutex{},
+		max:    float64(max),
+		ratio:  ratio,
+		rate:   DefaultBudgetRate,
+		tokens: float64(max),
+		clock:  SystemClock,
+	}
+
+	for _, opt := range opts {
+		opt(b)
+	}
+
+	b.last = b.clock.Now()
+
+	return b
+}
+
+// refill adds the tokens accumulated since the last call, the
+// caller must hold b.mu.
+func (b *Budget) refill() {
+	now := b.clock.Now()
+	elapsed := now.Sub(b.last)
+	b.last = now
+
+	if elapsed <= 0 || b.rate <= 0 {
+		return
+	}
+
+	b.tokens = min(b.max, b.tokens+elapsed.Seconds()*b.rate)
+}
+
+// Withdraw spends a token for a retry and reports whether the retry
+// is allowed.
+func (b *Budget) Withdraw() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refill()
+
+	if b.tokens < 1 {
+		return false
+	}
+
+	b.tokens--
+
+	return true
+}
+
+// Deposit records a success and refills the budget by its ratio.
+func (b *Budget) Deposit() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refill()
+	b.tokens = min(b.max, b.tokens+b.ratio)
+}
+
+// Tokens returns the number of tokens currently available.
+func (b *Budget) Tokens() float64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refill()
+
+	return b.tokens
+}
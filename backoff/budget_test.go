@@ -0,0 +1,97 @@
+package backoff
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type testBudget struct {
+	suite.Suite
+	now    time.Time
+	budget *Budget
+}
+
+type budgetClock struct {
+	Clock
+	now *time.Time
+}
+
+func (c budgetClock) Now() time.Time {
+	return *c.now
+}
+
+func TestBudgetSuite(t *testing.T) {
+	suite.Run(t, new(testBudget))
+}
+
+func (s *testBudget) SetupTest() {
+	s.now = time.Unix(0, 0)
+	clock := budgetClock{Clock: SystemClock, now: &s.now}
+	s.budget = NewBudget(2, 0.5, WithBudgetClock(clock), WithBudgetRate(0))
+}
+
+func (s *testBudget) TestWithdraw() {
+	s.Require().True(s.budget.Withdraw(), "budget must start full")
+	s.Require().True(s.budget.Withdraw(), "budget must start full")
+
+	errMsg := "withdraw must fail once the budget is empty"
+	s.Require().False(s.budget.Withdraw(), errMsg)
+}
+
+func (s *testBudget) TestDeposit() {
+	s.budget.Withdraw()
+	s.budget.Withdraw()
+
+	s.budget.Deposit()
+	s.Require().False(s.budget.Withdraw(), "half a token must not allow a retry")
+
+	s.budget.Deposit()
+	s.Require().True(s.budget.Withdraw(), "two successes must refill a token")
+
+	for range 10 {
+		s.budget.Deposit()
+	}
+
+	errMsg := "deposit must not exceed the budget size"
+	s.Require().Equal(float64(2), s.budget.Tokens(), errMsg)
+}
+
+func (s *testBudget) TestDefaultRatio() {
+	clock := budgetClock{Clock: SystemClock, now: &s.now}
+	budget := NewBudget(1, 0, WithBudgetClock(clock), WithBudgetRate(0))
+
+	budget.Withdraw()
+	budget.Deposit()
+
+	errMsg := "zero ratio must fall back to the default ratio"
+	s.Require().InDelta(DefaultBudgetRatio, budget.Tokens(), 1e-9, errMsg)
+}
+
+func (s *testBudget) TestRate() {
+	s.budget.rate = 1
+	s.budget.Withdraw()
+	s.budget.Withdraw()
+
+	s.now = s.now.Add(time.Second)
+
+	errMsg := "budget must refill over time"
+	s.Require().True(s.budget.Withdraw(), errMsg)
+}
+
+func (s *testBudget) TestRetry() {
+	policy := NewConstant(0)
+	fn := func(ctx context.Context) error {
+		return errTest
+	}
+
+	err := Retry(context.Background(), fn, WithPolicy(policy), WithBudget(s.budget))
+
+	var retryErr *RetryError
+	s.Require().ErrorAs(err, &retryErr, "error must be a *RetryError")
+	s.Require().ErrorIs(err, ErrBudgetExhausted, "error must wrap ErrBudgetExhausted")
+	s.Require().ErrorIs(err, errTest, "error must wrap the last attempt error")
+	s.Require().Equal(3, retryErr.Attempts, "budget must allow two retries")
+}
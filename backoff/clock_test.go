@@ -0,0 +1,92 @@
+package backoff_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mtchuikov/pkg/backoff"
+	"github.com/mtchuikov/pkg/backoff/backofftest"
+	"github.com/stretchr/testify/suite"
+)
+
+type testClock struct {
+	suite.Suite
+	ctx   context.Context
+	clock *backofftest.Clock
+}
+
+func TestClockSuite(t *testing.T) {
+	suite.Run(t, new(testClock))
+}
+
+func (s *testClock) SetupTest() {
+	s.ctx = context.Background()
+	s.clock = backofftest.NewClock(time.Unix(0, 0))
+}
+
+func (s *testClock) TestRetry() {
+	policy := backoff.NewList(time.Second, 2*time.Second, 4*time.Second)
+	start := s.clock.Now()
+
+	attempts := make(chan time.Time, 4)
+	fn := func(ctx context.Context) error {
+		attempts <- s.clock.Now()
+		return errors.New("test error")
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- backoff.Retry(s.ctx, fn,
+			backoff.WithPolicy(policy),
+			backoff.WithClock(s.clock),
+		)
+	}()
+
+	for _, delay := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second} {
+		s.clock.BlockUntil(1)
+		s.clock.Advance(delay)
+	}
+
+	err := <-done
+	s.Require().Error(err, "retry must give up once the policy is exhausted")
+
+	expected := []time.Duration{0, time.Second, 3 * time.Second, 7 * time.Second}
+	for _, offset := range expected {
+		attempt := <-attempts
+
+		errMsg := "attempt must happen exactly after the policy delay"
+		s.Require().Equal(start.Add(offset), attempt, errMsg)
+	}
+}
+
+func (s *testClock) TestTicker() {
+	policy := backoff.NewConstant(time.Minute)
+	ticker := backoff.NewTicker(policy, backoff.WithTickerClock(s.clock))
+	defer ticker.Stop()
+
+	start := <-ticker.C
+
+	s.clock.BlockUntil(1)
+	s.clock.Advance(time.Minute)
+
+	tick := <-ticker.C
+	errMsg := "ticker must tick after the policy delay"
+	s.Require().Equal(start.Add(time.Minute), tick, errMsg)
+}
+
+func (s *testClock) TestTimerStop() {
+	timer := s.clock.NewTimer(time.Second)
+
+	errMsg := "stop must report an active timer"
+	s.Require().True(timer.Stop(), errMsg)
+
+	s.clock.Advance(time.Second)
+
+	select {
+	case <-timer.C():
+		s.Require().FailNow("stopped timer must not fire")
+	default:
+	}
+}
@@ -0,0 +1,40 @@
+package backoff
+
+import "time"
+
+// Hooks let retry loops report what they are doing, for example to
+// log or to collect metrics. Every hook is optional.
+type Hooks struct {
+	// OnRetry is called after a failed attempt, before waiting delay
+	// for the next one.
+	OnRetry func(attempt int, delay time.Duration, err error)
+
+	// OnGiveUp is called once the loop stops retrying, err is the
+	// error returned to the caller.
+	OnGiveUp func(attempts int, err error)
+
+	// OnSuccess is called after a successful attempt, elapsed is the
+	// time spent since the first attempt.
+	OnSuccess func(attempts int, elapsed time.Duration)
+}
+
+// NotifyRetry calls OnRetry if it is set, h may be nil.
+func (h *Hooks) NotifyRetry(attempt int, delay time.Duration, err error) {
+	if h != nil && h.OnRetry != nil {
+		h.OnRetry(attempt, delay, err)
+	}
+}
+
+// NotifyGiveUp calls OnGiveUp if it is set, h may be nil.
+func (h *Hooks) NotifyGiveUp(attempts int, err error) {
+	if h != nil && h.OnGiveUp != nil {
+		h.OnGiveUp(attempts, err)
+	}
+}
+
+// NotifySuccess calls OnSuccess if it is set, h may be nil.
+func (h *Hooks) NotifySuccess(attempts int, elapsed time.Duration) {
+	if h != nil && h.OnSuccess != nil {
+		h.OnSuccess(attempts, elapsed)
+	}
+}
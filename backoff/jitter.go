@@ -0,0 +1,64 @@
+package backoff
+
+import "time"
+
+// Jitter spreads the delays returned by Backoff.Next so that many
+// clients backing off at the same time do not retry in lockstep.
+// It receives the backoff with its current interval, the delay
+// returned by the previous call to Next (zero after a reset) and a
+// random number in [0, 1). The result is clamped to [0, Max] by
+// the backoff.
+type Jitter func(b *Backoff, prev time.Duration, random float64) time.Duration
+
+// NoJitter returns the current interval as is.
+func NoJitter(b *Backoff, prev time.Duration, random float64) time.Duration {
+	return b.Current
+}
+
+// RandFactorJitter returns a random delay in the range
+// [current - RandFactor*current, current + RandFactor*current].
+// It is the default strategy of a backoff created with New.
+func RandFactorJitter(b *Backoff, prev time.Duration, random float64) time.Duration {
+	if b.RandFactor == 0 {
+		return b.Current
+	}
+
+	current := float64(b.Current)
+	delta := b.RandFactor * current
+
+	min := current - delta
+	max := current + delta
+
+	return time.Duration(min + random*(max-min))
+}
+
+// FullJitter returns a random delay in the range [0, current]. It
+// spreads clients the most, at the cost of sometimes retrying
+// almost immediately.
+func FullJitter(b *Backoff, prev time.Duration, random float64) time.Duration {
+	return time.Duration(random * float64(b.Current))
+}
+
+// EqualJitter returns a random delay in the range
+// [current/2, current], keeping half of the interval as a
+// guaranteed wait.
+func EqualJitter(b *Backoff, prev time.Duration, random float64) time.Duration {
+	half := float64(b.Current) / 2
+	return time.Duration(half + random*half)
+}
+
+// DecorrelatedJitter returns a random delay in the range
+// [Interval, prev*3], as described in the "Exponential Backoff And
+// Jitter" article of the AWS Architecture Blog. The delay grows
+// from the previous delay rather than from the current interval,
+// so Multiplier is not used.
+func DecorrelatedJitter(b *Backoff, prev time.Duration, random float64) time.Duration {
+	base := float64(b.Interval)
+
+	upper := 3 * float64(prev)
+	if upper < base {
+		upper = base
+	}
+
+	return time.Duration(base + random*(upper-base))
+}
@@ -0,0 +1,100 @@
+package backoff
+
+import (
+	"math/rand/v2"
+	"time"
+)
+
+type Option func(*Backoff)
+
+// WithRandSource sets the source of randomness used by Jitter, which
+// makes the delays returned by Next reproducible. The backoff does
+// not synchronize access to src.
+func WithRandSource(src rand.Source) Option {
+	return func(b *Backoff) {
+		b.rand = rand.New(src)
+	}
+}
+
+type RetryOption func(*retrier)
+
+// WithPolicy sets the policy used to compute the delay between
+// attempts, a Backoff created with New is used by default. The
+// policy is reset before the first attempt.
+func WithPolicy(policy Policy) RetryOption {
+	return func(r *retrier) {
+		r.policy = policy
+	}
+}
+
+// WithMaxAttempts limits the number of times fn is called, values
+// less than or equal to zero mean no limit.
+func WithMaxAttempts(attempts int) RetryOption {
+	return func(r *retrier) {
+		r.maxAttempts = attempts
+	}
+}
+
+// WithMaxElapsedTime limits the total time spent retrying, values
+// less than or equal to zero mean no limit. Retrying stops as soon
+// as the next delay would exceed the limit.
+func WithMaxElapsedTime(elapsed time.Duration) RetryOption {
+	return func(r *retrier) {
+		r.maxElapsedTime = elapsed
+	}
+}
+
+// WithClock sets the clock used to measure the elapsed time and to
+// wait between attempts, SystemClock is used by default.
+func WithClock(clock Clock) RetryOption {
+	return func(r *retrier) {
+		r.clock = clock
+	}
+}
+
+type TickerOption func(*Ticker)
+
+// WithTickerClock sets the clock used to wait between ticks,
+// SystemClock is used by default.
+func WithTickerClock(clock Clock) TickerOption {
+	return func(t *Ticker) {
+		t.clock = clock
+	}
+}
+
+// WithBudget makes every retry withdraw a token from budget and
+// every success deposit the ratio of the budget back. When the
+// budget is empty retrying stops at once with an error wrapping
+// ErrBudgetExhausted.
+func WithBudget(budget *Budget) RetryOption {
+	return func(r *retrier) {
+		r.budget = budget
+	}
+}
+
+// WithHooks sets the hooks called by the retry loop, by default the
+// Hooks of a *Backoff policy are used.
+func WithHooks(hooks Hooks) RetryOption {
+	return func(r *retrier) {
+		r.hooks = &hooks
+	}
+}
+
+type BudgetOption func(*Budget)
+
+// WithBudgetRate sets the number of tokens the budget regains per
+// second regardless of successes, zero disables the time based
+// refill.
+func WithBudgetRate(perSecond float64) BudgetOption {
+	return func(b *Budget) {
+		b.rate = perSecond
+	}
+}
+
+// WithBudgetClock sets the clock used to refill the budget over
+// time, SystemClock is used by default.
+func WithBudgetClock(clock Clock) BudgetOption {
+	return func(b *Budget) {
+		b.clock = clock
+	}
+}
@@ -0,0 +1,280 @@
+package backoff
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalidPolicy = errors.New("invalid backoff policy")
+
+// Policy is a schedule of delays between attempts. Next returns
+// the delay before the next attempt and false once the schedule is
+// exhausted and no more attempts should be made. Reset starts the
+// schedule over. Implementations are not safe for concurrent use.
+type Policy interface {
+	Next() (time.Duration, bool)
+	Reset()
+}
+
+var (
+	_ Policy = (*Backoff)(nil)
+	_ Policy = (*Constant)(nil)
+	_ Policy = (*Linear)(nil)
+	_ Policy = (*Fibonacci)(nil)
+	_ Policy = (*List)(nil)
+)
+
+// Constant waits the same interval before every attempt.
+type Constant struct {
+	Interval time.Duration
+}
+
+func NewConstant(interval time.Duration) *Constant {
+	return &Constant{Interval: interval}
+}
+
+func (c *Constant) Next() (time.Duration, bool) {
+	return c.Interval, true
+}
+
+func (c *Constant) Reset() {}
+
+// Linear starts at Interval and grows by Increment after every
+// attempt, up to Max.
+type Linear struct {
+	Interval  time.Duration
+	Increment time.Duration
+	Max       time.Duration
+	Current   time.Duration
+
+	// started reports whether Next has been called since the policy
+	// was created or reset, Current may legitimately be zero.
+	started bool
+}
+
+func NewLinear(interval, increment, max time.Duration) *Linear {
+	return &Linear{
+		Interval:  interval,
+		Increment: increment,
+		Max:       max,
+		Current:   0,
+		started:   false,
+	}
+}
+
+func (l *Linear) Next() (time.Duration, bool) {
+	if !l.started {
+		l.Current = l.Interval
+		l.started = true
+	} else {
+		l.Current += l.Increment
+	}
+
+	if l.Max > 0 && l.Current > l.Max {
+		l.Current = l.Max
+	}
+
+	return l.Current, true
+}
+
+func (l *Linear) Reset() {
+	l.Current = 0
+	l.started = false
+}
+
+// NewExponential returns a Backoff that multiplies the interval by
+// multiplier after every attempt, up to max, without jitter.
+func NewExponential(interval, max time.Duration, multiplier float64) *Backoff {
+	b := New()
+	b.Interval = interval
+	b.Max = max
+	b.Multiplier = multiplier
+	b.RandFactor = 0
+	b.Jitter = NoJitter
+
+	return b
+}
+
+// Fibonacci waits Interval multiplied by the next number of the
+// Fibonacci sequence (1, 1, 2, 3, 5, ...), up to Max.
+type Fibonacci struct {
+	Interval time.Duration
+	Max      time.Duration
+
+	prev    time.Duration
+	current time.Duration
+	started bool
+}
+
+func NewFibonacci(interval, max time.Duration) *Fibonacci {
+	return &Fibonacci{
+		Interval: interval,
+		Max:      max,
+	}
+}
+
+func (f *Fibonacci) Next() (time.Duration, bool) {
+	if !f.started {
+		f.current = f.Interval
+		f.started = true
+	} else {
+		f.prev, f.current = f.current, f.prev+f.current
+	}
+
+	if f.Max > 0 && f.current > f.Max {
+		f.current = f.Max
+	}
+
+	return f.current, true
+}
+
+func (f *Fibonacci) Reset() {
+	f.prev = 0
+	f.current = 0
+	f.started = false
+}
+
+// List walks through an explicit list of intervals and is
+// exhausted after the last one.
+type List struct {
+	Intervals []time.Duration
+
+	pos int
+}
+
+func NewList(intervals ...time.Duration) *List {
+	return &List{Intervals: intervals}
+}
+
+// ParseList parses a comma separated list of durations, such as
+// "1s,2s,5s,30s", into a List.
+func ParseList(list string) (*List, error) {
+	intervals, err := parseDurations(list)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewList(intervals...), nil
+}
+
+func (l *List) Next() (time.Duration, bool) {
+	if l.pos >= len(l.Intervals) {
+		return 0, false
+	}
+
+	next := l.Intervals[l.pos]
+	l.pos++
+
+	return next, true
+}
+
+func (l *List) Reset() {
+	l.pos = 0
+}
+
+// ParsePolicy builds a policy from a "<kind>:<args>" spec so that
+// the schedule can be chosen from configuration. Supported specs:
+//
+//	constant:<interval>
+//	linear:<interval>,<increment>,<max>
+//	exponential:<interval>,<max>,<multiplier>
+//	fibonacci:<interval>,<max>
+//	list:<interval>,<interval>,...
+func ParsePolicy(spec string) (Policy, error) {
+	kind, args, _ := strings.Cut(strings.TrimSpace(spec), ":")
+
+	switch kind {
+	case "constant":
+		d, err := parseDurationArgs(args, 1)
+		if err != nil {
+			return nil, err
+		}
+
+		return NewConstant(d[0]), nil
+	case "linear":
+		d, err := parseDurationArgs(args, 3)
+		if err != nil {
+			return nil, err
+		}
+
+		return NewLinear(d[0], d[1], d[2]), nil
+	case "exponential":
+		head, rawMultiplier, ok := cutLast(args, ",")
+		if !ok {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidPolicy, spec)
+		}
+
+		d, err := parseDurationArgs(head, 2)
+		if err != nil {
+			return nil, err
+		}
+
+		multiplier, err := strconv.ParseFloat(strings.TrimSpace(rawMultiplier), 64)
+		if err != nil || multiplier < 1 {
+			return nil, fmt.Errorf("%w: invalid multiplier %q", ErrInvalidPolicy, rawMultiplier)
+		}
+
+		return NewExponential(d[0], d[1], multiplier), nil
+	case "fibonacci":
+		d, err := parseDurationArgs(args, 2)
+		if err != nil {
+			return nil, err
+		}
+
+		return NewFibonacci(d[0], d[1]), nil
+	case "list":
+		return ParseList(args)
+	default:
+		return nil, fmt.Errorf("%w: unknown kind %q", ErrInvalidPolicy, kind)
+	}
+}
+
+func cutLast(s, sep string) (string, string, bool) {
+	idx := strings.LastIndex(s, sep)
+	if idx < 0 {
+		return s, "", false
+	}
+
+	return s[:idx], s[idx+len(sep):], true
+}
+
+func parseDurationArgs(args string, n int) ([]time.Duration, error) {
+	durations, err := parseDurations(args)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(durations) != n {
+		return nil, fmt.Errorf("%w: expected %d durations, got %d",
+			ErrInvalidPolicy, n, len(durations))
+	}
+
+	return durations, nil
+}
+
+func parseDurations(list string) ([]time.Duration, error) {
+	if strings.TrimSpace(list) == "" {
+		return nil, fmt.Errorf("%w: empty list of durations", ErrInvalidPolicy)
+	}
+
+	parts := strings.Split(list, ",")
+	durations := make([]time.Duration, 0, len(parts))
+
+	for _, part := range parts {
+		d, err := time.ParseDuration(strings.TrimSpace(part))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidPolicy, err)
+		}
+
+		if d < 0 {
+			return nil, fmt.Errorf("%w: negative duration %q", ErrInvalidPolicy, part)
+		}
+
+		durations = append(durations, d)
+	}
+
+	return durations, nil
+}
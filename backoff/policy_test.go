@@ -0,0 +1,111 @@
+package backoff
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type testPolicy struct {
+	suite.Suite
+}
+
+func TestPolicySuite(t *testing.T) {
+	suite.Run(t, new(testPolicy))
+}
+
+func (s *testPolicy) collect(policy Policy, n int) []time.Duration {
+	delays := make([]time.Duration, 0, n)
+	for range n {
+		next, ok := policy.Next()
+		if !ok {
+			break
+		}
+
+		delays = append(delays, next)
+	}
+
+	return delays
+}
+
+func (s *testPolicy) TestLinear() {
+	policy := NewLinear(time.Second, time.Second, 3*time.Second)
+
+	expected := []time.Duration{time.Second, 2 * time.Second, 3 * time.Second, 3 * time.Second}
+	errMsg := "linear policy must grow by 'increment' up to 'max'"
+	s.Require().Equal(expected, s.collect(policy, 4), errMsg)
+
+	policy = NewLinear(0, time.Second, 10*time.Second)
+
+	expected = []time.Duration{0, time.Second, 2 * time.Second}
+	errMsg = "linear policy must grow from a zero 'interval'"
+	s.Require().Equal(expected, s.collect(policy, 3), errMsg)
+
+	policy.Reset()
+	expected = []time.Duration{0, time.Second}
+	errMsg = "reset must start the linear policy over"
+	s.Require().Equal(expected, s.collect(policy, 2), errMsg)
+}
+
+func (s *testPolicy) TestExponential() {
+	policy := NewExponential(time.Second, 5*time.Second, 2)
+
+	expected := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second}
+	errMsg := "exponential policy must grow by 'multiplier' up to 'max'"
+	s.Require().Equal(expected, s.collect(policy, 4), errMsg)
+}
+
+func (s *testPolicy) TestFibonacci() {
+	policy := NewFibonacci(time.Second, 4*time.Second)
+
+	expected := []time.Duration{time.Second, time.Second, 2 * time.Second, 3 * time.Second, 4 * time.Second}
+	errMsg := "fibonacci policy must follow the fibonacci sequence up to 'max'"
+	s.Require().Equal(expected, s.collect(policy, 5), errMsg)
+
+	policy.Reset()
+	next, _ := policy.Next()
+	errMsg = "reset must start the sequence over"
+	s.Require().Equal(time.Second, next, errMsg)
+}
+
+func (s *testPolicy) TestList() {
+	policy, err := ParseList("1s, 2s,5s,30s")
+	s.Require().NoError(err, "list must be parsed")
+
+	expected := []time.Duration{time.Second, 2 * time.Second, 5 * time.Second, 30 * time.Second}
+	errMsg := "list policy must return every interval and then stop"
+	s.Require().Equal(expected, s.collect(policy, 10), errMsg)
+
+	policy.Reset()
+	_, ok := policy.Next()
+	errMsg = "reset must start the list over"
+	s.Require().True(ok, errMsg)
+}
+
+func (s *testPolicy) TestParsePolicy() {
+	specs := map[string][]time.Duration{
+		"constant:1s":           {time.Second, time.Second, time.Second},
+		" constant:250ms ":      {250 * time.Millisecond, 250 * time.Millisecond, 250 * time.Millisecond},
+		"linear:1s,500ms,2s":    {time.Second, 1500 * time.Millisecond, 2 * time.Second},
+		"exponential:1s,15s,2":  {time.Second, 2 * time.Second, 4 * time.Second},
+		"exponential:1s, 3s, 3": {time.Second, 3 * time.Second, 3 * time.Second},
+		"fibonacci:1s,30s":      {time.Second, time.Second, 2 * time.Second},
+		"list:1s,2s,5s,30s":     {time.Second, 2 * time.Second, 5 * time.Second},
+		"list:100ms":            {100 * time.Millisecond},
+	}
+
+	for spec, expected := range specs {
+		policy, err := ParsePolicy(spec)
+		s.Require().NoErrorf(err, "spec %q must be parsed", spec)
+		s.Require().Equalf(expected, s.collect(policy, 3), "spec %q parsed incorrectly", spec)
+	}
+
+	invalid := []string{"", "constant", "constant:1s,2s", "linear:1s", "exponential:1s,2s",
+		"exponential:1s,2s,0.5", "list:", "list:1s,abc", "unknown:1s", "constant:-1s"}
+
+	for _, spec := range invalid {
+		_, err := ParsePolicy(spec)
+		s.Require().ErrorIsf(err, ErrInvalidPolicy, "spec %q must be rejected", spec)
+	}
+}
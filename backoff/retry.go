@@ -0,0 +1,169 @@
+package backoff
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	DefaultMaxAttempts    = 0
+	DefaultMaxElapsedTime = 0
+)
+
+// PermanentError marks an error that must not be retried. It is
+// returned by Permanent and unwrapped by Retry before the error is
+// handed back to the caller.
+type PermanentError struct {
+	Err error
+}
+
+func (e *PermanentError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *PermanentError) Unwrap() error {
+	return e.Err
+}
+
+// Permanent wraps err so that Retry stops immediately after the
+// attempt that returned it. A nil err is returned as is.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return &PermanentError{Err: err}
+}
+
+// RetryError is returned by Retry and RetryValue when they give
+// up. It carries the number of attempts made and the error of the
+// last attempt.
+type RetryError struct {
+	Attempts int
+	Err      error
+}
+
+func (e *RetryError) Error() string {
+	return fmt.Sprintf("giving up after %d attempt(s): %v", e.Attempts, e.Err)
+}
+
+func (e *RetryError) Unwrap() error {
+	return e.Err
+}
+
+type retrier struct {
+	policy         Policy
+	maxAttempts    int
+	maxElapsedTime time.Duration
+	clock          Clock
+	budget         *Budget
+	hooks          *Hooks
+}
+
+func newRetrier(opts ...RetryOption) *retrier {
+	r := &retrier{
+		policy:         nil,
+		maxAttempts:    DefaultMaxAttempts,
+		maxElapsedTime: DefaultMaxElapsedTime,
+		clock:          SystemClock,
+		budget:         nil,
+		hooks:          nil,
+	}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	if r.policy == nil {
+		r.policy = New()
+	}
+
+	b, ok := r.policy.(*Backoff)
+	if r.hooks == nil && ok {
+		r.hooks = &b.Hooks
+	}
+
+	return r
+}
+
+func (r *retrier) giveUp(attempts int, err error) error {
+	retryErr := &RetryError{Attempts: attempts, Err: err}
+	r.hooks.NotifyGiveUp(attempts, retryErr)
+
+	return retryErr
+}
+
+// Retry calls fn until it succeeds, returns a permanent error, the
+// attempt or elapsed time limits are reached, the retry budget is
+// exhausted, or ctx is done. The delay between attempts is taken
+// from the configured Policy, unless the error of the last attempt
+// carries a delay requested by the server, see NextDelay. On failure
+// the returned error is a *RetryError wrapping the error of the
+// last attempt.
+func Retry(ctx context.Context, fn func(context.Context) error, opts ...RetryOption) error {
+	valueFn := func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, fn(ctx)
+	}
+
+	_, err := RetryValue(ctx, valueFn, opts...)
+	return err
+}
+
+// RetryValue is like Retry but returns the value produced by the
+// successful attempt.
+func RetryValue[T any](ctx context.Context, fn func(context.Context) (T, error), opts ...RetryOption) (T, error) {
+	r := newRetrier(opts...)
+	r.policy.Reset()
+
+	var zero T
+	start := r.clock.Now()
+
+	for attempt := 1; ; attempt++ {
+		val, err := fn(ctx)
+		if err == nil {
+			if r.budget != nil {
+				r.budget.Deposit()
+			}
+
+			r.hooks.NotifySuccess(attempt, r.clock.Now().Sub(start))
+
+			return val, nil
+		}
+
+		var permanent *PermanentError
+		if errors.As(err, &permanent) {
+			return zero, r.giveUp(attempt, permanent.Err)
+		}
+
+		if r.maxAttempts > 0 && attempt >= r.maxAttempts {
+			return zero, r.giveUp(attempt, err)
+		}
+
+		delay, ok := NextDelay(r.policy, err, r.clock)
+		if !ok {
+			return zero, r.giveUp(attempt, err)
+		}
+
+		if r.maxElapsedTime > 0 && r.clock.Now().Sub(start)+delay > r.maxElapsedTime {
+			return zero, r.giveUp(attempt, err)
+		}
+
+		if r.budget != nil && !r.budget.Withdraw() {
+			err = fmt.Errorf("%w: %w", ErrBudgetExhausted, err)
+			return zero, r.giveUp(attempt, err)
+		}
+
+		r.hooks.NotifyRetry(attempt, delay, err)
+
+		timer := r.clock.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			err = fmt.Errorf("%w: %w", ctx.Err(), err)
+			return zero, r.giveUp(attempt, err)
+		case <-timer.C():
+		}
+	}
+}
@@ -0,0 +1,267 @@
+package backoff
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"connectrpc.com/connect"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+const (
+	RetryAfterHeader = "Retry-After"
+
+	// RetryInfoType is the type of the error detail gRPC and connect
+	// servers use to request a delay before the next attempt.
+	RetryInfoType = "google.rpc.RetryInfo"
+)
+
+// RetryAfterer is implemented by errors that carry a delay requested
+// by the server before the next attempt.
+type RetryAfterer interface {
+	RetryAfter() time.Duration
+}
+
+// RetryAfterError attaches a server provided delay to an error.
+type RetryAfterError struct {
+	Err   error
+	Delay time.Duration
+}
+
+func (e *RetryAfterError) Error() string {
+	return fmt.Sprintf("%v (retry after %v)", e.Err, e.Delay)
+}
+
+func (e *RetryAfterError) Unwrap() error {
+	return e.Err
+}
+
+func (e *RetryAfterError) RetryAfter() time.Duration {
+	return e.Delay
+}
+
+// RetryAfter returns the delay requested by the server that produced
+// err, if any. It looks for a RetryAfterer or a *connect.Error in the
+// chain of err.
+func RetryAfter(err error) (time.Duration, bool) {
+	return retryAfter(err, SystemClock.Now())
+}
+
+// retryAfter is like RetryAfter, but turns an HTTP date into a delay
+// relative to now.
+func retryAfter(err error, now time.Time) (time.Duration, bool) {
+	if err == nil {
+		return 0, false
+	}
+
+	var retryAfterer RetryAfterer
+	if errors.As(err, &retryAfterer) {
+		return max(retryAfterer.RetryAfter(), 0), true
+	}
+
+	var connectErr *connect.Error
+	if errors.As(err, &connectErr) {
+		return connectRetryAfter(connectErr, now)
+	}
+
+	return 0, false
+}
+
+// maxDelayer is implemented by the policies that have an upper bound
+// on their delays.
+type maxDelayer interface {
+	maxDelay() time.Duration
+}
+
+func (b *Backoff) maxDelay() time.Duration {
+	return b.Max
+}
+
+func (l *Linear) maxDelay() time.Duration {
+	return l.Max
+}
+
+func (f *Fibonacci) maxDelay() time.Duration {
+	return f.Max
+}
+
+// maxDelay of a List is its longest interval.
+func (l *List) maxDelay() time.Duration {
+	if len(l.Intervals) == 0 {
+		return 0
+	}
+
+	return slices.Max(l.Intervals)
+}
+
+// NextDelay returns the next delay of policy, overridden by the
+// delay requested by the server that produced err. The requested
+// delay is clamped to the Max of Backoff, Linear and Fibonacci and
+// to the longest interval of List. Constant has no upper bound, so
+// the requested delay is used as is. The schedule of policy still
+// advances so that the following delays keep growing. clock is used
+// to turn an HTTP date into a delay and defaults to SystemClock when
+// nil.
+func NextDelay(policy Policy, err error, clock Clock) (time.Duration, bool) {
+	delay, ok := policy.Next()
+	if !ok {
+		return 0, false
+	}
+
+	if clock == nil {
+		clock = SystemClock
+	}
+
+	retryAfter, ok := retryAfter(err, clock.Now())
+	if !ok {
+		return delay, true
+	}
+
+	bounded, ok := policy.(maxDelayer)
+	if ok && bounded.maxDelay() > 0 {
+		retryAfter = min(retryAfter, bounded.maxDelay())
+	}
+
+	return retryAfter, true
+}
+
+// NextAfter is like Next, but returns the delay requested by the
+// server that produced err when there is one, clamped to Max, see
+// NextDelay.
+func (b *Backoff) NextAfter(err error) (time.Duration, bool) {
+	return NextDelay(b, err, SystemClock)
+}
+
+// ParseRetryAfter parses the value of a Retry-After header, which is
+// either a number of seconds or an HTTP date. Dates in the past
+// result in a zero delay.
+func ParseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+
+	seconds, err := strconv.ParseInt(value, 10, 64)
+	if err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	date, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+
+	return max(date.Sub(now), 0), true
+}
+
+// HTTPRetryAfter returns the delay requested by the Retry-After
+// header of resp. The header is only honored for the 429, 503 and
+// 3xx status codes, as described in RFC 9110.
+func HTTPRetryAfter(resp *http.Response) (time.Duration, bool) {
+	return httpRetryAfter(resp, SystemClock.Now())
+}
+
+// httpRetryAfter is like HTTPRetryAfter, but turns an HTTP date into
+// a delay relative to now.
+func httpRetryAfter(resp *http.Response, now time.Time) (time.Duration, bool) {
+	if resp == nil {
+		return 0, false
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+	case resp.StatusCode == http.StatusServiceUnavailable:
+	case resp.StatusCode >= 300 && resp.StatusCode < 400:
+	default:
+		return 0, false
+	}
+
+	return ParseRetryAfter(resp.Header.Get(RetryAfterHeader), now)
+}
+
+// ConnectRetryAfter returns the delay requested by the Retry-After
+// metadata of err or, without it, by its RetryInfo detail. It is only
+// honored for the CodeResourceExhausted and CodeUnavailable codes.
+func ConnectRetryAfter(err *connect.Error) (time.Duration, bool) {
+	return connectRetryAfter(err, SystemClock.Now())
+}
+
+// connectRetryAfter is like ConnectRetryAfter, but turns an HTTP date
+// into a delay relative to now.
+func connectRetryAfter(err *connect.Error, now time.Time) (time.Duration, bool) {
+	if err == nil {
+		return 0, false
+	}
+
+	code := err.Code()
+	if code != connect.CodeResourceExhausted && code != connect.CodeUnavailable {
+		return 0, false
+	}
+
+	delay, ok := ParseRetryAfter(err.Meta().Get(RetryAfterHeader), now)
+	if ok {
+		return delay, true
+	}
+
+	for _, detail := range err.Details() {
+		if detail.Type() != RetryInfoType {
+			continue
+		}
+
+		delay, ok = parseRetryInfo(detail.Bytes())
+		if ok {
+			return delay, true
+		}
+	}
+
+	return 0, false
+}
+
+// parseRetryInfo returns the retry_delay of a google.rpc.RetryInfo
+// encoded in b. It reads the wire format directly, so that the
+// package does not depend on the generated googleapis types.
+func parseRetryInfo(b []byte) (time.Duration, bool) {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return 0, false
+		}
+		b = b[n:]
+
+		if num != 1 || typ != protowire.BytesType {
+			n = protowire.ConsumeFieldValue(num, typ, b)
+			if n < 0 {
+				return 0, false
+			}
+			b = b[n:]
+
+			continue
+		}
+
+		value, n := protowire.ConsumeBytes(b)
+		if n < 0 {
+			return 0, false
+		}
+
+		var delay durationpb.Duration
+		err := proto.Unmarshal(value, &delay)
+		if err != nil || delay.CheckValid() != nil {
+			return 0, false
+		}
+
+		return max(delay.AsDuration(), 0), true
+	}
+
+	return 0, false
+}
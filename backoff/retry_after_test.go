@@ -0,0 +1,162 @@
+package backoff
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"connectrpc.com/connect"
+	"github.com/stretchr/testify/suite"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+type testRetryAfter struct {
+	suite.Suite
+	now time.Time
+}
+
+func TestRetryAfterSuite(t *testing.T) {
+	suite.Run(t, new(testRetryAfter))
+}
+
+func (s *testRetryAfter) SetupTest() {
+	s.now = time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+}
+
+func (s *testRetryAfter) TestParseRetryAfter() {
+	delay, ok := ParseRetryAfter("120", s.now)
+	s.Require().True(ok, "seconds must be parsed")
+	s.Require().Equal(2*time.Minute, delay, "seconds must be converted to a delay")
+
+	date := s.now.Add(30 * time.Second).Format(http.TimeFormat)
+	delay, ok = ParseRetryAfter(date, s.now)
+	s.Require().True(ok, "http date must be parsed")
+	s.Require().Equal(30*time.Second, delay, "http date must be converted to a delay")
+
+	date = s.now.Add(-time.Minute).Format(http.TimeFormat)
+	delay, ok = ParseRetryAfter(date, s.now)
+	s.Require().True(ok, "http date in the past must be parsed")
+	s.Require().Zero(delay, "http date in the past must result in a zero delay")
+
+	for _, value := range []string{"", "-1", "soon"} {
+		_, ok = ParseRetryAfter(value, s.now)
+		s.Require().Falsef(ok, "value %q must be rejected", value)
+	}
+}
+
+func (s *testRetryAfter) TestHTTPRetryAfter() {
+	resp := &http.Response{
+		StatusCode: http.StatusTooManyRequests,
+		Header:     http.Header{RetryAfterHeader: []string{"5"}},
+	}
+
+	delay, ok := HTTPRetryAfter(resp)
+	s.Require().True(ok, "retry after must be honored for 429")
+	s.Require().Equal(5*time.Second, delay, "retry after must be parsed")
+
+	resp.StatusCode = http.StatusInternalServerError
+	_, ok = HTTPRetryAfter(resp)
+	s.Require().False(ok, "retry after must be ignored for 500")
+}
+
+func (s *testRetryAfter) TestConnectRetryAfter() {
+	connectErr := connect.NewError(connect.CodeUnavailable, errTest)
+	connectErr.Meta().Set(RetryAfterHeader, "3")
+
+	delay, ok := RetryAfter(fmt.Errorf("wrapped: %w", connectErr))
+	s.Require().True(ok, "retry after must be found in a wrapped connect error")
+	s.Require().Equal(3*time.Second, delay, "retry after must be parsed")
+
+	connectErr = connect.NewError(connect.CodeInternal, errTest)
+	connectErr.Meta().Set(RetryAfterHeader, "3")
+
+	_, ok = RetryAfter(connectErr)
+	s.Require().False(ok, "retry after must be ignored for CodeInternal")
+}
+
+func (s *testRetryAfter) TestNextAfter() {
+	backoff := New()
+	backoff.RandFactor = 0
+	backoff.Max = time.Minute
+
+	err := &RetryAfterError{Err: errTest, Delay: time.Second}
+	delay, _ := backoff.NextAfter(err)
+	s.Require().Equal(time.Second, delay, "server delay must override the backoff")
+
+	err.Delay = time.Hour
+	delay, _ = backoff.NextAfter(err)
+	s.Require().Equal(time.Minute, delay, "server delay must be clamped to 'max'")
+
+	delay, _ = backoff.NextAfter(errTest)
+	s.Require().Equal(backoff.Current, delay, "backoff must be used without server delay")
+}
+
+func (s *testRetryAfter) TestNextDelay() {
+	err := &RetryAfterError{Err: errTest, Delay: 24 * time.Hour}
+
+	policies := map[string]Policy{
+		"linear":    NewLinear(time.Second, time.Second, time.Minute),
+		"fibonacci": NewFibonacci(time.Second, time.Minute),
+		"list":      NewList(time.Second, time.Minute),
+	}
+
+	for name, policy := range policies {
+		delay, ok := NextDelay(policy, err, nil)
+		s.Require().Truef(ok, "%s policy must not be exhausted", name)
+		s.Require().Equalf(time.Minute, delay, "server delay must be clamped to the max of the %s policy", name)
+	}
+
+	delay, _ := NextDelay(NewConstant(time.Second), err, nil)
+	s.Require().Equal(24*time.Hour, delay, "server delay must be used as is with a constant policy")
+}
+
+func (s *testRetryAfter) TestRetryAfterClock() {
+	resp := &http.Response{
+		StatusCode: http.StatusServiceUnavailable,
+		Header: http.Header{
+			RetryAfterHeader: []string{s.now.Add(time.Minute).Format(http.TimeFormat)},
+		},
+	}
+
+	delay, ok := httpRetryAfter(resp, s.now)
+	s.Require().True(ok, "retry after must be honored for 503")
+	s.Require().Equal(time.Minute, delay, "http date must be relative to now")
+
+	clock := budgetClock{Clock: SystemClock, now: &s.now}
+	policy := NewConstant(time.Second)
+	err := connect.NewError(connect.CodeUnavailable, errTest)
+	err.Meta().Set(RetryAfterHeader, resp.Header.Get(RetryAfterHeader))
+
+	delay, _ = NextDelay(policy, err, clock)
+	s.Require().Equal(time.Minute, delay, "http date must be relative to the clock of NextDelay")
+}
+
+func (s *testRetryAfter) TestConnectRetryInfo() {
+	duration, err := proto.Marshal(durationpb.New(1500 * time.Millisecond))
+	s.Require().NoError(err, "duration must be marshaled")
+
+	retryInfo := protowire.AppendTag(nil, 1, protowire.BytesType)
+	retryInfo = protowire.AppendBytes(retryInfo, duration)
+
+	detail, err := connect.NewErrorDetail(&anypb.Any{
+		TypeUrl: "type.googleapis.com/" + RetryInfoType,
+		Value:   retryInfo,
+	})
+	s.Require().NoError(err, "detail must be created")
+
+	connectErr := connect.NewError(connect.CodeResourceExhausted, errTest)
+	connectErr.AddDetail(detail)
+
+	delay, ok := ConnectRetryAfter(connectErr)
+	s.Require().True(ok, "retry info detail must be honored")
+	s.Require().Equal(1500*time.Millisecond, delay, "retry delay must be read from the detail")
+
+	connectErr.Meta().Set(RetryAfterHeader, "3")
+
+	delay, _ = ConnectRetryAfter(connectErr)
+	s.Require().Equal(3*time.Second, delay, "retry after metadata must take precedence")
+}
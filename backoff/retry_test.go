@@ -0,0 +1,163 @@
+package backoff
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/suite"
+)
+
+var errTest = errors.New("test error")
+
+type testRetry struct {
+	suite.Suite
+	ctx     context.Context
+	backoff *Backoff
+}
+
+func TestRetrySuite(t *testing.T) {
+	suite.Run(t, new(testRetry))
+}
+
+func (s *testRetry) SetupTest() {
+	s.ctx = context.Background()
+	s.backoff = New()
+	s.backoff.Interval = time.Millisecond
+	s.backoff.Max = time.Millisecond
+	s.backoff.RandFactor = 0
+}
+
+func (s *testRetry) TestSuccess() {
+	calls := 0
+	fn := func(ctx context.Context) (int, error) {
+		calls++
+		if calls < 3 {
+			return 0, errTest
+		}
+
+		return 42, nil
+	}
+
+	val, err := RetryValue(s.ctx, fn, WithPolicy(s.backoff))
+
+	s.Require().NoError(err, "retry must succeed on the third attempt")
+	s.Require().Equal(42, val, "retry must return the value of the successful attempt")
+	s.Require().Equal(3, calls, "fn must be called until it succeeds")
+}
+
+func (s *testRetry) TestMaxAttempts() {
+	calls := 0
+	fn := func(ctx context.Context) error {
+		calls++
+		return errTest
+	}
+
+	err := Retry(s.ctx, fn, WithPolicy(s.backoff), WithMaxAttempts(4))
+
+	var retryErr *RetryError
+	s.Require().ErrorAs(err, &retryErr, "error must be a *RetryError")
+	s.Require().Equal(4, retryErr.Attempts, "error must contain the number of attempts")
+	s.Require().ErrorIs(err, errTest, "error must wrap the last attempt error")
+	s.Require().Equal(4, calls, "fn must be called max attempts times")
+}
+
+func (s *testRetry) TestPermanent() {
+	calls := 0
+	fn := func(ctx context.Context) error {
+		calls++
+		return Permanent(errTest)
+	}
+
+	err := Retry(s.ctx, fn, WithPolicy(s.backoff))
+
+	var permanent *PermanentError
+	s.Require().False(errors.As(err, &permanent), "permanent wrapper must be removed")
+	s.Require().ErrorIs(err, errTest, "error must wrap the permanent error")
+	s.Require().Equal(1, calls, "permanent error must stop retrying")
+}
+
+func (s *testRetry) TestMaxElapsedTime() {
+	s.backoff.Interval = time.Hour
+	s.backoff.Max = time.Hour
+
+	fn := func(ctx context.Context) error {
+		return errTest
+	}
+
+	err := Retry(s.ctx, fn, WithPolicy(s.backoff), WithMaxElapsedTime(time.Minute))
+
+	var retryErr *RetryError
+	s.Require().ErrorAs(err, &retryErr, "error must be a *RetryError")
+	s.Require().Equal(1, retryErr.Attempts, "delay above the limit must stop retrying")
+}
+
+func (s *testRetry) TestContextCancelled() {
+	s.backoff.Interval = time.Hour
+	s.backoff.Max = time.Hour
+
+	ctx, cancel := context.WithCancel(s.ctx)
+	fn := func(ctx context.Context) error {
+		cancel()
+		return errTest
+	}
+
+	err := Retry(ctx, fn, WithPolicy(s.backoff))
+
+	s.Require().ErrorIs(err, context.Canceled, "error must wrap the context error")
+	s.Require().ErrorIs(err, errTest, "error must wrap the last attempt error")
+}
+
+func (s *testRetry) TestHooks() {
+	retries := make([]int, 0, 2)
+	giveUps := 0
+	successes := 0
+
+	s.backoff.Hooks = Hooks{
+		OnRetry: func(attempt int, delay time.Duration, err error) {
+			retries = append(retries, attempt)
+		},
+		OnGiveUp: func(attempts int, err error) {
+			giveUps++
+		},
+		OnSuccess: func(attempts int, elapsed time.Duration) {
+			successes++
+		},
+	}
+
+	fn := func(ctx context.Context) error {
+		return errTest
+	}
+
+	Retry(s.ctx, fn, WithPolicy(s.backoff), WithMaxAttempts(3))
+
+	s.Require().Equal([]int{1, 2}, retries, "on retry must be called before every retry")
+	s.Require().Equal(1, giveUps, "on give up must be called once")
+	s.Require().Zero(successes, "on success must not be called")
+
+	Retry(s.ctx, func(ctx context.Context) error { return nil }, WithPolicy(s.backoff))
+	s.Require().Equal(1, successes, "on success must be called once")
+}
+
+func (s *testRetry) TestZerologHooks() {
+	var buf bytes.Buffer
+	log := zerolog.New(&buf)
+
+	fn := func(ctx context.Context) error {
+		return errTest
+	}
+
+	Retry(s.ctx, fn,
+		WithPolicy(s.backoff),
+		WithMaxAttempts(2),
+		WithHooks(NewZerologHooks(log)),
+	)
+
+	output := buf.String()
+	s.Require().Contains(output, `"attempt":1`, "retry must be logged with the attempt")
+	s.Require().Contains(output, `"attempts":2`, "give up must be logged with the attempts")
+	s.Require().Contains(output, errTest.Error(), "error must be logged")
+}
@@ -0,0 +1,136 @@
+package backoff
+
+import (
+	"sync"
+	"time"
+)
+
+// Ticker delivers ticks on C at intervals taken from a Policy, like
+// time.Ticker does at a fixed interval. The first tick is sent
+// immediately, and the delay before the next tick starts only after
+// the previous one has been received, so a slow consumer never
+// gets a burst of stale ticks.
+//
+// C is closed once the ticker is stopped or the policy is
+// exhausted, which makes it possible to write reconnect loops as
+//
+//	for range ticker.C {
+//		if err := connect(); err == nil {
+//			ticker.Stop()
+//		}
+//	}
+//
+// Stop and Reset are safe to call from any goroutine.
+type Ticker struct {
+	C <-chan time.Time
+
+	c  chan time.Time
+	mu sync.Mutex
+
+	policy Policy
+	clock  Clock
+
+	reset    chan struct{}
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewTicker returns a Ticker driven by policy. The policy is reset
+// before the first tick and must not be used elsewhere while the
+// ticker is running.
+func NewTicker(policy Policy, opts ...TickerOption) *Ticker {
+	c := make(chan time.Time)
+
+	t := &Ticker{
+		C:        c,
+		c:        c,
+		mu:       sync.Mutex{},
+		policy:   policy,
+		clock:    SystemClock,
+		reset:    make(chan struct{}, 1),
+		stop:     make(chan struct{}),
+		stopOnce: sync.Once{},
+	}
+
+	for _, opt := range opts {
+		opt(t)
+	}
+
+	policy.Reset()
+	go t.run()
+
+	return t
+}
+
+func (t *Ticker) next() (time.Duration, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.policy.Next()
+}
+
+func (t *Ticker) run() {
+	defer close(t.c)
+
+	// send delivers a tick and reports whether the ticker should
+	// keep running, a reset while waiting for the receiver drops
+	// the pending tick.
+	send := func(tick time.Time) bool {
+		select {
+		case <-t.stop:
+			return false
+		case <-t.reset:
+		case t.c <- tick:
+		}
+
+		return true
+	}
+
+	if !send(t.clock.Now()) {
+		return
+	}
+
+	for {
+		delay, ok := t.next()
+		if !ok {
+			return
+		}
+
+		timer := t.clock.NewTimer(delay)
+
+		select {
+		case <-t.stop:
+			timer.Stop()
+			return
+		case <-t.reset:
+			timer.Stop()
+			continue
+		case tick := <-timer.C():
+			if !send(tick) {
+				return
+			}
+		}
+	}
+}
+
+// Reset starts the policy over, the pending tick is dropped and
+// the next one is sent after the first delay of the policy. Reset
+// has no effect once the ticker is stopped or exhausted.
+func (t *Ticker) Reset() {
+	t.mu.Lock()
+	t.policy.Reset()
+	t.mu.Unlock()
+
+	select {
+	case t.reset <- struct{}{}:
+	default:
+	}
+}
+
+// Stop turns off the ticker and closes C. It is safe to call Stop
+// more than once.
+func (t *Ticker) Stop() {
+	t.stopOnce.Do(func() {
+		close(t.stop)
+	})
+}
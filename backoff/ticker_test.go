@@ -0,0 +1,76 @@
+package backoff
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type testTicker struct {
+	suite.Suite
+	timeout time.Duration
+}
+
+func TestTickerSuite(t *testing.T) {
+	suite.Run(t, new(testTicker))
+}
+
+func (s *testTicker) SetupTest() {
+	s.timeout = time.Second
+}
+
+func (s *testTicker) receive(ticker *Ticker) (time.Time, bool) {
+	select {
+	case tick, ok := <-ticker.C:
+		return tick, ok
+	case <-time.After(s.timeout):
+		s.Require().FailNow("ticker must tick before timeout")
+		return time.Time{}, false
+	}
+}
+
+func (s *testTicker) TestExhausted() {
+	ticker := NewTicker(NewList(time.Millisecond, time.Millisecond))
+	defer ticker.Stop()
+
+	ticks := 0
+	for range ticker.C {
+		ticks++
+	}
+
+	errMsg := "ticker must tick immediately and once per interval, got %d ticks"
+	s.Require().Equalf(3, ticks, errMsg, ticks)
+}
+
+func (s *testTicker) TestStop() {
+	ticker := NewTicker(NewConstant(time.Millisecond))
+
+	_, ok := s.receive(ticker)
+	s.Require().True(ok, "first tick must be delivered")
+
+	ticker.Stop()
+	ticker.Stop()
+
+	for {
+		_, ok = s.receive(ticker)
+		if !ok {
+			break
+		}
+	}
+
+	s.Require().False(ok, "channel must be closed after stop")
+}
+
+func (s *testTicker) TestReset() {
+	ticker := NewTicker(NewList(time.Millisecond, time.Hour))
+	defer ticker.Stop()
+
+	s.receive(ticker)
+	s.receive(ticker)
+
+	ticker.Reset()
+
+	_, ok := s.receive(ticker)
+	s.Require().True(ok, "reset must start the policy over instead of waiting an hour")
+}
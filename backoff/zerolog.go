@@ -0,0 +1,40 @@
+package backoff
+
+import (
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// NewZerologHooks returns hooks that log retries to log. The error
+// and the message are written under the field names configured by
+// logging.NewZerolog. Retries are logged at the warn level, giving
+// up at the error level, and successes at the debug level, or at the
+// info level when they needed more than one attempt.
+func NewZerologHooks(log zerolog.Logger) Hooks {
+	return Hooks{
+		OnRetry: func(attempt int, delay time.Duration, err error) {
+			log.Warn().
+				Err(err).
+				Int("attempt", attempt).
+				Dur("delay", delay).
+				Msg("attempt failed, retrying")
+		},
+		OnGiveUp: func(attempts int, err error) {
+			log.Error().
+				Err(err).
+				Int("attempts", attempts).
+				Msg("giving up retrying")
+		},
+		OnSuccess: func(attempts int, elapsed time.Duration) {
+			event := log.Debug()
+			if attempts > 1 {
+				event = log.Info()
+			}
+
+			event.Int("attempts", attempts).
+				Dur("elapsed", elapsed).
+				Msg("attempt succeeded")
+		},
+	}
+}
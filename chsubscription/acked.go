@@ -0,0 +1,409 @@
+package chsubscription
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	DefaultVisibilityTimeout = 30 * time.Second
+	DefaultMaxAttempts       = 5
+)
+
+type envelopeState int
+
+const (
+	envelopePending envelopeState = iota
+	envelopeAcked
+	envelopeNacked
+)
+
+// Envelope wraps a message delivered by an AckedChSubscription. The
+// subscriber must call Ack once the message is processed or Nack to
+// have it delivered again right away, otherwise it is delivered again
+// after the visibility timeout. Only the first call counts, and calls
+// on an envelope whose message has already been delivered again are
+// ignored.
+type Envelope[ChT any] struct {
+	Value   ChT
+	Attempt int // 1 on the first delivery
+
+	id    uint64
+	state envelopeState
+	sub   *AckedSubscription[ChT]
+}
+
+func (e *Envelope[ChT]) Ack() {
+	e.sub.settle(e, envelopeAcked)
+}
+
+func (e *Envelope[ChT]) Nack() {
+	e.sub.settle(e, envelopeNacked)
+}
+
+// AckedChSubscription broadcasts values to a group of subscribers
+// with at-least-once delivery. Every subscriber gets its own copy of
+// a message and must acknowledge it. Messages that are nacked or not
+// acknowledged within the visibility timeout are delivered again, up
+// to the max attempts, and then notified to the dead-letter group.
+//
+// Notify never blocks and never drops messages: each subscriber
+// queues them until it receives them, so a subscriber that stops
+// reading makes its queue grow.
+type AckedChSubscription[ChT any] struct {
+	// mu serializes the changes of items.
+	mu    sync.Mutex
+	items atomic.Pointer[[]*AckedSubscription[ChT]]
+
+	visibilityTimeout time.Duration
+	maxAttempts       int
+	deadLetter        *ChSubscription[ChT]
+}
+
+func NewAcked[ChT any](opts ...AckedOption) *AckedChSubscription[ChT] {
+	c := &ackedConfig{
+		visibilityTimeout: DefaultVisibilityTimeout,
+		maxAttempts:       DefaultMaxAttempts,
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	s := &AckedChSubscription[ChT]{
+		mu:                sync.Mutex{},
+		visibilityTimeout: c.visibilityTimeout,
+		maxAttempts:       c.maxAttempts,
+		deadLetter:        New[ChT](),
+	}
+
+	s.items.Store(&[]*AckedSubscription[ChT]{})
+
+	return s
+}
+
+func (s *AckedChSubscription[ChT]) snapshot() []*AckedSubscription[ChT] {
+	return *s.items.Load()
+}
+
+// DeadLetter returns the group the messages are notified to once a
+// subscriber has run out of attempts.
+func (s *AckedChSubscription[ChT]) DeadLetter() *ChSubscription[ChT] {
+	return s.deadLetter
+}
+
+// Subscribe adds a subscriber that holds at most bufSize envelopes
+// received but not settled yet, zero or less means no limit. The
+// envelopes wait in the queue of the subscriber rather than in its
+// channel, so the visibility timeout of an envelope starts once the
+// subscriber receives it.
+func (s *AckedChSubscription[ChT]) Subscribe(bufSize int) *AckedSubscription[ChT] {
+	sub := &AckedSubscription[ChT]{
+		ch:                make(chan *Envelope[ChT]),
+		maxInflight:       bufSize,
+		visibilityTimeout: s.visibilityTimeout,
+		maxAttempts:       s.maxAttempts,
+		deadLetter:        s.deadLetter,
+		mu:                sync.Mutex{},
+		queue:             ring[*Envelope[ChT]]{},
+		inflight:          make(map[uint64]inflight[ChT]),
+		dead:              nil,
+		wake:              make(chan struct{}, 1),
+		done:              make(chan struct{}),
+		stopped:           make(chan struct{}),
+		closeOnce:         sync.Once{},
+	}
+
+	sub.detach = func() {
+		s.remove(sub)
+	}
+
+	s.mu.Lock()
+	items := slices.Clone(s.snapshot())
+	items = append(items, sub)
+	s.items.Store(&items)
+	s.mu.Unlock()
+
+	go sub.run()
+
+	return sub
+}
+
+// Notify queues val for every subscriber. It stops as soon as ctx is
+// done, the subscribers that have not been reached yet miss val.
+func (s *AckedChSubscription[ChT]) Notify(ctx context.Context, val ChT) {
+	for _, item := range s.snapshot() {
+		if ctx.Err() != nil {
+			return
+		}
+
+		item.enqueue(val)
+	}
+}
+
+// Unsubscribe removes sub from the group and closes its channel, it
+// is the same as calling sub.Close.
+func (s *AckedChSubscription[ChT]) Unsubscribe(sub *AckedSubscription[ChT]) {
+	sub.Close()
+}
+
+func (s *AckedChSubscription[ChT]) remove(sub *AckedSubscription[ChT]) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	idx := slices.Index(s.snapshot(), sub)
+	if idx < 0 {
+		return
+	}
+
+	items := slices.Delete(slices.Clone(s.snapshot()), idx, idx+1)
+	s.items.Store(&items)
+}
+
+// Close closes all the subscriptions and the dead-letter group, the
+// messages that have not been acknowledged are lost.
+func (s *AckedChSubscription[ChT]) Close() {
+	s.mu.Lock()
+	items := s.snapshot()
+	s.items.Store(&[]*AckedSubscription[ChT]{})
+	s.mu.Unlock()
+
+	for _, item := range items {
+		item.close()
+	}
+
+	s.deadLetter.Close()
+}
+
+type inflight[ChT any] struct {
+	env      *Envelope[ChT]
+	deadline time.Time
+}
+
+// AckedSubscription is a handle to a single subscriber of an
+// AckedChSubscription.
+type AckedSubscription[ChT any] struct {
+	// ch is unbuffered, so an envelope is put into it only when the
+	// subscriber receives it.
+	ch          chan *Envelope[ChT]
+	maxInflight int
+
+	visibilityTimeout time.Duration
+	maxAttempts       int
+	deadLetter        *ChSubscription[ChT]
+
+	// mu guards the queue, the in-flight messages and the state of
+	// the envelopes.
+	mu       sync.Mutex
+	queue    ring[*Envelope[ChT]]
+	inflight map[uint64]inflight[ChT]
+	nextID   uint64
+	dead     []ChT
+
+	// wake tells the delivery goroutine that the queue or the
+	// in-flight messages have changed.
+	wake chan struct{}
+
+	detach    func()
+	done      chan struct{}
+	stopped   chan struct{}
+	closeOnce sync.Once
+}
+
+// C returns the channel the envelopes are delivered to. It is closed
+// when the subscription or its group is closed.
+func (s *AckedSubscription[ChT]) C() <-chan *Envelope[ChT] {
+	return s.ch
+}
+
+// Close removes the subscription from its group and closes the
+// channel. It is safe to call Close more than once.
+func (s *AckedSubscription[ChT]) Close() {
+	s.detach()
+	s.close()
+}
+
+func (s *AckedSubscription[ChT]) close() {
+	s.closeOnce.Do(func() {
+		close(s.done)
+		<-s.stopped
+		close(s.ch)
+	})
+}
+
+func (s *AckedSubscription[ChT]) enqueue(val ChT) {
+	s.mu.Lock()
+	s.nextID++
+	s.queue.push(&Envelope[ChT]{
+		Value:   val,
+		Attempt: 1,
+		id:      s.nextID,
+		state:   envelopePending,
+		sub:     s,
+	})
+	s.mu.Unlock()
+
+	s.signal()
+}
+
+func (s *AckedSubscription[ChT]) signal() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (s *AckedSubscription[ChT]) settle(env *Envelope[ChT], state envelopeState) {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.flushDead()
+	defer s.mu.Unlock()
+
+	if env.state != envelopePending {
+		return
+	}
+
+	env.state = state
+
+	// An envelope that is not in flight has either been received
+	// before the delivery goroutine recorded it, which then handles
+	// the new state, or been delivered again already.
+	entry, ok := s.inflight[env.id]
+	if !ok || entry.env != env {
+		return
+	}
+
+	delete(s.inflight, env.id)
+
+	if state == envelopeNacked {
+		s.retry(env)
+	}
+
+	// The subscriber has room for another envelope.
+	s.signal()
+}
+
+// retry queues env for another attempt, or sets it aside for the
+// dead-letter group once it has run out of attempts. The caller must
+// hold s.mu and call flushDead after releasing it.
+func (s *AckedSubscription[ChT]) retry(env *Envelope[ChT]) {
+	if s.maxAttempts > 0 && env.Attempt >= s.maxAttempts {
+		s.dead = append(s.dead, env.Value)
+		return
+	}
+
+	s.queue.push(&Envelope[ChT]{
+		Value:   env.Value,
+		Attempt: env.Attempt + 1,
+		id:      env.id,
+		state:   envelopePending,
+		sub:     s,
+	})
+}
+
+// flushDead notifies the dead-letter group about the messages set
+// aside by retry.
+func (s *AckedSubscription[ChT]) flushDead() {
+	s.mu.Lock()
+	dead := s.dead
+	s.dead = nil
+	s.mu.Unlock()
+
+	for _, val := range dead {
+		s.deadLetter.Notify(context.Background(), val)
+	}
+}
+
+// expire retries the in-flight messages whose visibility timeout has
+// passed and returns the time until the next one expires, or a
+// negative duration if nothing is in flight. The caller must hold
+// s.mu.
+func (s *AckedSubscription[ChT]) expire(now time.Time) time.Duration {
+	next := time.Duration(-1)
+
+	for id, entry := range s.inflight {
+		wait := entry.deadline.Sub(now)
+		if wait > 0 {
+			if next < 0 || wait < next {
+				next = wait
+			}
+
+			continue
+		}
+
+		delete(s.inflight, id)
+		s.retry(entry.env)
+	}
+
+	return next
+}
+
+// delivered records env as in flight once the subscriber has
+// received it, unless the subscriber has already settled it.
+func (s *AckedSubscription[ChT]) delivered(env *Envelope[ChT]) {
+	s.mu.Lock()
+	defer s.flushDead()
+	defer s.mu.Unlock()
+
+	switch env.state {
+	case envelopePending:
+		s.inflight[env.id] = inflight[ChT]{
+			env:      env,
+			deadline: time.Now().Add(s.visibilityTimeout),
+		}
+	case envelopeNacked:
+		s.retry(env)
+	}
+}
+
+func (s *AckedSubscription[ChT]) run() {
+	defer close(s.stopped)
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	var next *Envelope[ChT]
+	for {
+		s.mu.Lock()
+		if next == nil {
+			next, _ = s.queue.pop()
+		}
+
+		wait := s.expire(time.Now())
+		if next == nil {
+			next, _ = s.queue.pop()
+		}
+
+		full := s.maxInflight > 0 && len(s.inflight) >= s.maxInflight
+		s.mu.Unlock()
+
+		s.flushDead()
+
+		var timerC <-chan time.Time
+		if wait >= 0 {
+			timer.Reset(wait)
+			timerC = timer.C
+		}
+
+		var out chan *Envelope[ChT]
+		if next != nil && !full {
+			out = s.ch
+		}
+
+		select {
+		case <-s.done:
+			return
+		case <-s.wake:
+		case <-timerC:
+		case out <- next:
+			s.delivered(next)
+			next = nil
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package chsubscription
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type testAcked struct {
+	suite.Suite
+	ctx     context.Context
+	bufSize int
+	timeout time.Duration
+	chsub   *AckedChSubscription[int]
+}
+
+func TestAckedSuite(t *testing.T) {
+	suite.Run(t, new(testAcked))
+}
+
+func (s *testAcked) SetupTest() {
+	s.ctx = context.Background()
+	s.bufSize = 3
+	s.timeout = 20 * time.Millisecond
+	s.chsub = NewAcked[int](
+		WithVisibilityTimeout(s.timeout),
+		WithMaxAttempts(2),
+	)
+}
+
+func (s *testAcked) TearDownTest() {
+	s.chsub.Close()
+}
+
+func (s *testAcked) receive(sub *AckedSubscription[int]) *Envelope[int] {
+	select {
+	case env, ok := <-sub.C():
+		errMsg := "channel must be open"
+		s.Require().True(ok, errMsg)
+
+		return env
+	case <-time.After(time.Second):
+		errMsg := "envelope must be delivered"
+		s.Require().FailNow(errMsg)
+		return nil
+	}
+}
+
+func (s *testAcked) TestAck() {
+	sub := s.chsub.Subscribe(s.bufSize)
+
+	s.chsub.Notify(s.ctx, 1)
+
+	env := s.receive(sub)
+	s.Require().Equal(1, env.Value, "envelope must carry the value")
+	s.Require().Equal(1, env.Attempt, "first delivery must be attempt 1")
+
+	env.Ack()
+
+	select {
+	case <-sub.C():
+		s.Require().FailNow("acked message must not be delivered again")
+	case <-time.After(3 * s.timeout):
+	}
+}
+
+func (s *testAcked) TestRedeliver() {
+	sub := s.chsub.Subscribe(s.bufSize)
+
+	s.chsub.Notify(s.ctx, 1)
+	s.receive(sub)
+
+	env := s.receive(sub)
+	s.Require().Equal(1, env.Value, "message must be delivered again after the visibility timeout")
+	s.Require().Equal(2, env.Attempt, "redelivery must increase the attempt")
+
+	env.Ack()
+}
+
+func (s *testAcked) TestNack() {
+	chsub := NewAcked[int](WithVisibilityTimeout(time.Hour))
+	defer chsub.Close()
+
+	sub := chsub.Subscribe(s.bufSize)
+
+	chsub.Notify(s.ctx, 1)
+	s.receive(sub).Nack()
+
+	env := s.receive(sub)
+	s.Require().Equal(2, env.Attempt, "nacked message must be delivered again right away")
+}
+
+func (s *testAcked) TestDeadLetter() {
+	dead := s.chsub.DeadLetter().Subscribe(s.bufSize)
+	sub := s.chsub.Subscribe(s.bufSize)
+
+	s.chsub.Notify(s.ctx, 1)
+	s.receive(sub).Nack()
+	s.receive(sub).Nack()
+
+	select {
+	case val := <-dead.C():
+		s.Require().Equal(1, val, "message must be moved to the dead-letter group")
+	case <-time.After(time.Second):
+		s.Require().FailNow("message must be moved to the dead-letter group after max attempts")
+	}
+}
+
+func (s *testAcked) TestSlowConsumer() {
+	dead := s.chsub.DeadLetter().Subscribe(s.bufSize)
+	sub := s.chsub.Subscribe(s.bufSize)
+
+	for val := range s.bufSize {
+		s.chsub.Notify(s.ctx, val)
+	}
+
+	// Every message is processed within the visibility timeout, but
+	// the last ones wait longer than it before being received.
+	for val := range s.bufSize {
+		env := s.receive(sub)
+		s.Require().Equal(val, env.Value, "messages must be delivered in order")
+		s.Require().Equal(1, env.Attempt, "message acked in time must not be delivered again")
+
+		time.Sleep(s.timeout * 3 / 4)
+		env.Ack()
+	}
+
+	select {
+	case env := <-sub.C():
+		s.Require().FailNowf("acked message must not be delivered again", "got %d", env.Value)
+	case val := <-dead.C():
+		s.Require().FailNowf("acked message must not be moved to the dead-letter group", "got %d", val)
+	case <-time.After(3 * s.timeout):
+	}
+}
+
+func (s *testAcked) TestMaxInflight() {
+	sub := s.chsub.Subscribe(1)
+
+	s.chsub.Notify(s.ctx, 1)
+	s.chsub.Notify(s.ctx, 2)
+
+	env := s.receive(sub)
+
+	select {
+	case <-sub.C():
+		s.Require().FailNow("subscriber must not receive more than bufSize unsettled envelopes")
+	case <-time.After(s.timeout / 2):
+	}
+
+	env.Ack()
+	s.Require().Equal(2, s.receive(sub).Value, "settling an envelope must make room for the next one")
+}
+
+func (s *testAcked) TestClose() {
+	sub := s.chsub.Subscribe(s.bufSize)
+	sub.Close()
+
+	_, ok := <-sub.C()
+	s.Require().False(ok, "channel must be closed")
+	s.Require().Len(s.chsub.snapshot(), 0, "subscriber must be removed")
+}
@@ -0,0 +1,62 @@
+package chsubscription
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+func benchmarkNotify(b *testing.B, subscribers int) {
+	ctx := context.Background()
+	chsub := New[int]()
+	defer chsub.Close()
+
+	for range subscribers {
+		sub := chsub.Subscribe(1024)
+
+		go func() {
+			for range sub.C() {
+			}
+		}()
+	}
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		val := 0
+		for pb.Next() {
+			chsub.Notify(ctx, val)
+			val++
+		}
+	})
+}
+
+func BenchmarkNotify(b *testing.B) {
+	for _, subscribers := range []int{1, 100, 10_000} {
+		b.Run(fmt.Sprintf("subscribers=%d", subscribers), func(b *testing.B) {
+			benchmarkNotify(b, subscribers)
+		})
+	}
+}
+
+func BenchmarkNotifyWithChurn(b *testing.B) {
+	ctx := context.Background()
+	chsub := New[int]()
+	defer chsub.Close()
+
+	for range 100 {
+		chsub.Subscribe(1024)
+	}
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		val := 0
+		for pb.Next() {
+			if val%100 == 0 {
+				chsub.Subscribe(1).Close()
+			}
+
+			chsub.Notify(ctx, val)
+			val++
+		}
+	})
+}
@@ -0,0 +1,43 @@
+// Package bridge exports a chsubscription.ChSubscription to other
+// processes. A Server sends every value notified to a group to the
+// connected clients as length-prefixed frames, and a Client notifies
+// the values it receives to a local group, reconnecting with a
+// backoff whenever the connection is lost.
+//
+// A frame is a 4-byte big-endian payload length followed by the
+// payload encoded with a Codec. Any stream listener works, usually a
+// Unix domain socket or TCP.
+package bridge
+
+import (
+	"time"
+
+	"github.com/mtchuikov/pkg/backoff"
+)
+
+const (
+	DefaultMaxFrameSize      = 16 << 20
+	DefaultBufSize           = 64
+	DefaultReconnectInterval = 100 * time.Millisecond
+	DefaultReconnectMax      = 10 * time.Second
+)
+
+func newConfig(opts ...Option) *config {
+	b := backoff.New()
+	b.Interval = DefaultReconnectInterval
+	b.Max = DefaultReconnectMax
+
+	c := &config{
+		codec:        JSON,
+		maxFrameSize: DefaultMaxFrameSize,
+		bufSize:      DefaultBufSize,
+		backoff:      b,
+		clock:        backoff.SystemClock,
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
@@ -0,0 +1,133 @@
+package bridge
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mtchuikov/pkg/chsubscription"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/suite"
+)
+
+type event struct {
+	ID   int
+	Name string
+}
+
+type testBridge struct {
+	suite.Suite
+	ctx    context.Context
+	cancel context.CancelFunc
+	path   string
+	remote *chsubscription.ChSubscription[event]
+	local  *chsubscription.ChSubscription[event]
+}
+
+func TestBridgeSuite(t *testing.T) {
+	suite.Run(t, new(testBridge))
+}
+
+func (s *testBridge) SetupTest() {
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+	s.path = filepath.Join(s.T().TempDir(), "bridge.sock")
+	s.remote = chsubscription.New[event]()
+	s.local = chsubscription.New[event]()
+}
+
+func (s *testBridge) TearDownTest() {
+	s.cancel()
+	s.remote.Close()
+	s.local.Close()
+}
+
+func (s *testBridge) serve(opts ...Option) *Server[event] {
+	ln, err := net.Listen("unix", s.path)
+	s.Require().NoError(err, "listener must be created")
+
+	server := NewServer(zerolog.Nop(), s.remote, opts...)
+	go server.Serve(s.ctx, ln)
+
+	return server
+}
+
+func (s *testBridge) connect(opts ...Option) {
+	client := NewClient(zerolog.Nop(), "unix", s.path, s.local, opts...)
+	go client.Run(s.ctx)
+
+	s.Require().Eventually(func() bool {
+		return s.remote.Stats().Subscribers == 1
+	}, time.Second, time.Millisecond, "client must connect to the server")
+}
+
+func (s *testBridge) receive(sub *chsubscription.Subscription[event]) event {
+	select {
+	case val := <-sub.C():
+		return val
+	case <-time.After(time.Second):
+		s.Require().FailNow("value must be received through the bridge")
+		return event{}
+	}
+}
+
+func (s *testBridge) TestCodecs() {
+	codecs := map[string]Codec{
+		"json": JSON,
+		"gob":  Gob,
+	}
+
+	for name, codec := range codecs {
+		s.Run(name, func() {
+			s.path = filepath.Join(s.T().TempDir(), name+".sock")
+
+			server := s.serve(WithCodec(codec))
+			defer server.Close()
+
+			sub := s.local.Subscribe(1)
+			defer sub.Close()
+
+			s.connect(WithCodec(codec))
+
+			want := event{ID: 1, Name: name}
+			s.remote.Notify(s.ctx, want)
+
+			s.Require().Equal(want, s.receive(sub), "value must survive the round trip")
+		})
+	}
+}
+
+func (s *testBridge) TestReconnect() {
+	server := s.serve()
+
+	sub := s.local.Subscribe(1)
+	defer sub.Close()
+
+	s.connect()
+	server.Close()
+
+	s.Require().Eventually(func() bool {
+		return s.remote.Stats().Subscribers == 0
+	}, time.Second, time.Millisecond, "closed server must drop the connection")
+
+	server = s.serve()
+	defer server.Close()
+
+	s.connect()
+
+	want := event{ID: 2, Name: "reconnected"}
+	s.remote.Notify(s.ctx, want)
+
+	s.Require().Equal(want, s.receive(sub), "client must receive values after reconnecting")
+}
+
+func (s *testBridge) TestFrameTooLarge() {
+	var buf bytes.Buffer
+	err := writeFrame(&buf, make([]byte, 8))
+	s.Require().NoError(err, "frame must be written")
+
+	_, err = readFrame(&buf, 4)
+	s.Require().ErrorIs(err, ErrFrameTooLarge, "frame larger than the limit must be rejected")
+}
@@ -0,0 +1,100 @@
+package bridge
+
+import (
+	"context"
+	"net"
+
+	"github.com/mtchuikov/pkg/chsubscription"
+	"github.com/rs/zerolog"
+)
+
+// Client receives the values sent by a Server and notifies them to a
+// local group. Values sent while the client is disconnected are lost.
+type Client[T any] struct {
+	log     zerolog.Logger
+	network string
+	address string
+	local   *chsubscription.ChSubscription[T]
+	cfg     *config
+}
+
+// NewClient creates a client for the server listening on address,
+// network is one of the networks accepted by net.Dial, such as "unix"
+// or "tcp".
+func NewClient[T any](log zerolog.Logger, network, address string, local *chsubscription.ChSubscription[T], opts ...Option) *Client[T] {
+	return &Client[T]{
+		log:     log,
+		network: network,
+		address: address,
+		local:   local,
+		cfg:     newConfig(opts...),
+	}
+}
+
+// Run connects to the server and notifies the received values to the
+// local group until ctx is done, it always returns the error of ctx.
+// Lost connections are reestablished after the delays of the backoff,
+// which is reset once a connection has been made.
+func (c *Client[T]) Run(ctx context.Context) error {
+	for {
+		connected, err := c.session(ctx)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		if connected {
+			c.cfg.backoff.Reset()
+		}
+
+		delay, _ := c.cfg.backoff.Next()
+
+		c.log.Debug().
+			Err(err).
+			Dur("delay", delay).
+			Msg("bridge connection lost, reconnecting")
+
+		timer := c.cfg.clock.NewTimer(delay)
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C():
+		}
+	}
+}
+
+// session reads values from a single connection until it fails, it
+// reports whether the connection has been made.
+func (c *Client[T]) session(ctx context.Context) (bool, error) {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, c.network, c.address)
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+
+	stop := context.AfterFunc(ctx, func() {
+		conn.Close()
+	})
+	defer stop()
+
+	for {
+		payload, err := readFrame(conn, c.cfg.maxFrameSize)
+		if err != nil {
+			return true, err
+		}
+
+		var val T
+		err = c.cfg.codec.Unmarshal(payload, &val)
+		if err != nil {
+			c.log.Error().
+				Err(err).
+				Msg("failed to decode value")
+
+			continue
+		}
+
+		c.local.Notify(ctx, val)
+	}
+}
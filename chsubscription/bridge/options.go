@@ -0,0 +1,57 @@
+package bridge
+
+import (
+	"github.com/mtchuikov/pkg/backoff"
+)
+
+type config struct {
+	codec        Codec
+	maxFrameSize int
+	bufSize      int
+	backoff      *backoff.Backoff
+	clock        backoff.Clock
+}
+
+// Option configures a Server or a Client, the options that only make
+// sense for one of them are ignored by the other.
+type Option func(*config)
+
+// WithCodec sets the codec of the frames, JSON by default.
+func WithCodec(codec Codec) Option {
+	return func(c *config) {
+		c.codec = codec
+	}
+}
+
+// WithMaxFrameSize limits the size of the frames a Client accepts,
+// the connection is dropped when a larger frame is received.
+func WithMaxFrameSize(size int) Option {
+	return func(c *config) {
+		c.maxFrameSize = size
+	}
+}
+
+// WithBufSize sets the buffer of the subscription a Server creates
+// for every connection. Values that do not fit into it while the
+// connection is slow are dropped.
+func WithBufSize(size int) Option {
+	return func(c *config) {
+		c.bufSize = size
+	}
+}
+
+// WithBackoff sets the backoff a Client waits for between
+// reconnections.
+func WithBackoff(b *backoff.Backoff) Option {
+	return func(c *config) {
+		c.backoff = b
+	}
+}
+
+// WithClock sets the clock a Client waits with between
+// reconnections.
+func WithClock(clock backoff.Clock) Option {
+	return func(c *config) {
+		c.clock = clock
+	}
+}
@@ -0,0 +1,187 @@
+package bridge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+
+	"github.com/mtchuikov/pkg/chsubscription"
+	"github.com/rs/zerolog"
+)
+
+var ErrServerClosed = errors.New("bridge server closed")
+
+// Server sends the values notified to a group to every connected
+// Client. Each connection gets its own subscription, so a slow client
+// only loses its own values.
+type Server[T any] struct {
+	log   zerolog.Logger
+	chsub *chsubscription.ChSubscription[T]
+	cfg   *config
+
+	mu        sync.Mutex
+	closed    bool
+	listeners map[net.Listener]struct{}
+	conns     map[net.Conn]struct{}
+	wg        sync.WaitGroup
+}
+
+func NewServer[T any](log zerolog.Logger, chsub *chsubscription.ChSubscription[T], opts ...Option) *Server[T] {
+	return &Server[T]{
+		log:       log,
+		chsub:     chsub,
+		cfg:       newConfig(opts...),
+		mu:        sync.Mutex{},
+		closed:    false,
+		listeners: make(map[net.Listener]struct{}),
+		conns:     make(map[net.Conn]struct{}),
+		wg:        sync.WaitGroup{},
+	}
+}
+
+// Serve accepts connections on ln until ctx is done or the server is
+// closed, it always closes ln. It returns ErrServerClosed after Close
+// and the error of ctx once it is done.
+func (s *Server[T]) Serve(ctx context.Context, ln net.Listener) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		ln.Close()
+		return ErrServerClosed
+	}
+
+	s.listeners[ln] = struct{}{}
+	s.mu.Unlock()
+
+	stop := context.AfterFunc(ctx, func() {
+		ln.Close()
+	})
+	defer stop()
+
+	defer func() {
+		s.mu.Lock()
+		delete(s.listeners, ln)
+		s.mu.Unlock()
+
+		ln.Close()
+	}()
+
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if s.isClosed() {
+				return ErrServerClosed
+			}
+
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+
+			return fmt.Errorf("failed to accept connection: %w", err)
+		}
+
+		if !s.track(conn) {
+			conn.Close()
+			return ErrServerClosed
+		}
+
+		go s.serveConn(ctx, conn)
+	}
+}
+
+func (s *Server[T]) isClosed() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.closed
+}
+
+// track registers conn so that Close can drop it, it returns false
+// once the server is closed.
+func (s *Server[T]) track(conn net.Conn) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return false
+	}
+
+	s.conns[conn] = struct{}{}
+	s.wg.Add(1)
+
+	return true
+}
+
+func (s *Server[T]) untrack(conn net.Conn) {
+	s.mu.Lock()
+	delete(s.conns, conn)
+	s.mu.Unlock()
+
+	conn.Close()
+	s.wg.Done()
+}
+
+func (s *Server[T]) serveConn(ctx context.Context, conn net.Conn) {
+	defer s.untrack(conn)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Clients never send anything, reading only tells when the
+	// connection is gone.
+	go func() {
+		io.Copy(io.Discard, conn)
+		cancel()
+	}()
+
+	sub := s.chsub.SubscribeContext(ctx, s.cfg.bufSize)
+	defer sub.Close()
+
+	s.log.Debug().
+		Str("remote", conn.RemoteAddr().String()).
+		Msg("bridge client connected")
+
+	for val := range sub.All(ctx) {
+		payload, err := s.cfg.codec.Marshal(val)
+		if err != nil {
+			s.log.Error().
+				Err(err).
+				Msg("failed to encode value")
+
+			continue
+		}
+
+		err = writeFrame(conn, payload)
+		if err != nil {
+			s.log.Debug().
+				Err(err).
+				Msg("failed to send value, dropping connection")
+
+			return
+		}
+	}
+}
+
+// Close stops all the listeners passed to Serve, drops the
+// connections and waits for their goroutines to finish. The group
+// itself is left open.
+func (s *Server[T]) Close() error {
+	s.mu.Lock()
+	s.closed = true
+
+	for ln := range s.listeners {
+		ln.Close()
+	}
+
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.mu.Unlock()
+
+	s.wg.Wait()
+
+	return nil
+}
@@ -0,0 +1,227 @@
+package chsubscription
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// TopicSeparator separates the tokens of a topic, such as
+	// "orders.created".
+	TopicSeparator = "."
+
+	// WildcardOne matches exactly one token of a topic, for example
+	// "orders.*" matches "orders.created" but not "orders.created.eu".
+	WildcardOne = "*"
+
+	// WildcardTail matches one or more trailing tokens of a topic and
+	// may only be used as the last token of a pattern, for example
+	// "orders.>" matches both "orders.created" and "orders.created.eu".
+	WildcardTail = ">"
+)
+
+var (
+	ErrInvalidTopic   = errors.New("invalid topic")
+	ErrInvalidPattern = errors.New("invalid topic pattern")
+)
+
+type brokerNode[ChT any] struct {
+	children map[string]*brokerNode[ChT]
+	items    []*Subscription[ChT]
+}
+
+func newBrokerNode[ChT any]() *brokerNode[ChT] {
+	return &brokerNode[ChT]{
+		children: make(map[string]*brokerNode[ChT]),
+		items:    nil,
+	}
+}
+
+// Broker routes values to subscribers by topic. Publishers send a
+// value to a topic made of tokens separated by dots, and subscribers
+// receive the values of every topic matching their pattern, which
+// may contain the WildcardOne and WildcardTail tokens. Patterns are
+// kept in a trie, so the cost of Publish depends on the number of
+// tokens in the topic rather than on the number of subscriptions.
+type Broker[ChT any] struct {
+	mu   sync.RWMutex
+	root *brokerNode[ChT]
+}
+
+func NewBroker[ChT any]() *Broker[ChT] {
+	return &Broker[ChT]{
+		mu:   sync.RWMutex{},
+		root: newBrokerNode[ChT](),
+	}
+}
+
+func splitTopic(topic string) ([]string, bool) {
+	if topic == "" {
+		return nil, false
+	}
+
+	tokens := strings.Split(topic, TopicSeparator)
+	if slices.Contains(tokens, "") {
+		return nil, false
+	}
+
+	return tokens, true
+}
+
+// Subscribe adds a subscriber with a buffer of bufSize values for
+// the topics matching pattern, see ChSubscription.Subscribe for the
+// options.
+func (b *Broker[ChT]) Subscribe(pattern string, bufSize int, opts ...SubscribeOption) (*Subscription[ChT], error) {
+	tokens, ok := splitTopic(pattern)
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidPattern, pattern)
+	}
+
+	tail := slices.Index(tokens, WildcardTail)
+	if tail >= 0 && tail != len(tokens)-1 {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidPattern, pattern)
+	}
+
+	sub := newSubscription[ChT](bufSize, opts...)
+	sub.detach = func() {
+		b.remove(tokens, sub)
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	node := b.root
+	for _, token := range tokens {
+		child, ok := node.children[token]
+		if !ok {
+			child = newBrokerNode[ChT]()
+			node.children[token] = child
+		}
+
+		node = child
+	}
+
+	node.items = append(node.items, sub)
+
+	return sub, nil
+}
+
+// Publish sends val to every subscriber whose pattern matches topic.
+// The topic must not contain wildcards. Like ChSubscription.Notify,
+// it stops as soon as ctx is done. The matching subscriptions are
+// collected under the lock and the values are sent after releasing
+// it, so a blocked subscriber never keeps others from subscribing or
+// unsubscribing.
+func (b *Broker[ChT]) Publish(ctx context.Context, topic string, val ChT) error {
+	tokens, ok := splitTopic(topic)
+	if !ok || slices.Contains(tokens, WildcardOne) || slices.Contains(tokens, WildcardTail) {
+		return fmt.Errorf("%w: %q", ErrInvalidTopic, topic)
+	}
+
+	b.mu.RLock()
+	items := b.match(nil, b.root, tokens)
+	b.mu.RUnlock()
+
+	now := time.Now()
+	for _, item := range items {
+		if ctx.Err() != nil {
+			break
+		}
+
+		item.deliver(ctx, val, now)
+	}
+
+	return nil
+}
+
+// match appends the subscriptions of the patterns matching tokens to
+// items, the caller must hold b.mu.
+func (b *Broker[ChT]) match(items []*Subscription[ChT], node *brokerNode[ChT], tokens []string) []*Subscription[ChT] {
+	if len(tokens) == 0 {
+		return append(items, node.items...)
+	}
+
+	child, ok := node.children[tokens[0]]
+	if ok {
+		items = b.match(items, child, tokens[1:])
+	}
+
+	child, ok = node.children[WildcardOne]
+	if ok {
+		items = b.match(items, child, tokens[1:])
+	}
+
+	child, ok = node.children[WildcardTail]
+	if ok {
+		items = append(items, child.items...)
+	}
+
+	return items
+}
+
+// Unsubscribe removes sub from the broker and closes its channel, it
+// is the same as calling sub.Close.
+func (b *Broker[ChT]) Unsubscribe(sub *Subscription[ChT]) {
+	sub.Close()
+}
+
+// remove deletes sub from the node of its pattern and prunes the
+// nodes left without subscriptions and children.
+func (b *Broker[ChT]) remove(tokens []string, sub *Subscription[ChT]) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	path := make([]*brokerNode[ChT], 0, len(tokens)+1)
+	path = append(path, b.root)
+
+	node := b.root
+	for _, token := range tokens {
+		child, ok := node.children[token]
+		if !ok {
+			return
+		}
+
+		path = append(path, child)
+		node = child
+	}
+
+	idx := slices.Index(node.items, sub)
+	if idx < 0 {
+		return
+	}
+
+	node.items = slices.Delete(node.items, idx, idx+1)
+
+	for i := len(tokens) - 1; i >= 0; i-- {
+		child := path[i+1]
+		if len(child.items) > 0 || len(child.children) > 0 {
+			break
+		}
+
+		delete(path[i].children, tokens[i])
+	}
+}
+
+func (b *Broker[ChT]) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var closeNode func(node *brokerNode[ChT])
+	closeNode = func(node *brokerNode[ChT]) {
+		for _, item := range node.items {
+			item.close()
+		}
+
+		for _, child := range node.children {
+			closeNode(child)
+		}
+	}
+
+	closeNode(b.root)
+	b.root = newBrokerNode[ChT]()
+}
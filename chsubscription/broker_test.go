@@ -0,0 +1,133 @@
+package chsubscription
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type testBroker struct {
+	suite.Suite
+	ctx     context.Context
+	bufSize int
+	broker  *Broker[string]
+}
+
+func TestBrokerSuite(t *testing.T) {
+	suite.Run(t, new(testBroker))
+}
+
+func (s *testBroker) SetupTest() {
+	s.ctx = context.Background()
+	s.bufSize = 3
+	s.broker = NewBroker[string]()
+}
+
+func (s *testBroker) TearDownTest() {
+	s.broker.Close()
+}
+
+func (s *testBroker) subscribe(pattern string) *Subscription[string] {
+	sub, err := s.broker.Subscribe(pattern, s.bufSize)
+
+	errMsg := "pattern %q must be accepted, got '%v'"
+	s.Require().NoErrorf(err, errMsg, pattern, err)
+
+	return sub
+}
+
+func (s *testBroker) received(sub *Subscription[string]) []string {
+	values := make([]string, 0, len(sub.C()))
+	for len(sub.C()) > 0 {
+		values = append(values, <-sub.C())
+	}
+
+	return values
+}
+
+func (s *testBroker) TestPublish() {
+	exact := s.subscribe("orders.created")
+	one := s.subscribe("orders.*")
+	tail := s.subscribe("orders.>")
+	other := s.subscribe("users.*")
+
+	s.Require().NoError(s.broker.Publish(s.ctx, "orders.created", "a"))
+	s.Require().NoError(s.broker.Publish(s.ctx, "orders.created.eu", "b"))
+	s.Require().NoError(s.broker.Publish(s.ctx, "orders", "c"))
+
+	s.Require().Equal([]string{"a"}, s.received(exact), "exact pattern must match only its topic")
+	s.Require().Equal([]string{"a"}, s.received(one), "'*' must match exactly one token")
+	s.Require().Equal([]string{"a", "b"}, s.received(tail), "'>' must match one or more tokens")
+	s.Require().Empty(s.received(other), "unrelated pattern must not match")
+}
+
+func (s *testBroker) TestInvalid() {
+	for _, pattern := range []string{"", "orders..created", "orders.>.created", ".orders"} {
+		_, err := s.broker.Subscribe(pattern, s.bufSize)
+		s.Require().ErrorIsf(err, ErrInvalidPattern, "pattern %q must be rejected", pattern)
+	}
+
+	for _, topic := range []string{"", "orders.*", "orders.>", "orders."} {
+		err := s.broker.Publish(s.ctx, topic, "a")
+		s.Require().ErrorIsf(err, ErrInvalidTopic, "topic %q must be rejected", topic)
+	}
+}
+
+func (s *testBroker) TestUnsubscribe() {
+	sub := s.subscribe("orders.created.eu")
+
+	s.broker.Unsubscribe(sub)
+
+	_, open := <-sub.C()
+	s.Require().False(open, "channel must be closed after unsubscribe")
+
+	errMsg := "empty nodes must be pruned after unsubscribe"
+	s.Require().Empty(s.broker.root.children, errMsg)
+}
+
+func (s *testBroker) TestClose() {
+	sub1 := s.subscribe("orders.*")
+	sub2 := s.subscribe("users.>")
+
+	s.broker.Close()
+
+	_, open := <-sub1.C()
+	s.Require().False(open, "channel sub1 must be closed")
+
+	_, open = <-sub2.C()
+	s.Require().False(open, "channel sub2 must be closed")
+
+	sub1.Close()
+}
+
+func (s *testBroker) TestCloseBlocked() {
+	sub, err := s.broker.Subscribe("orders.*", 0, Block())
+	s.Require().NoError(err, "pattern must be accepted")
+
+	published := make(chan struct{})
+	go func() {
+		s.broker.Publish(s.ctx, "orders.created", "order")
+		close(published)
+	}()
+
+	closed := make(chan struct{})
+	go func() {
+		// Give the publisher time to block on the subscriber.
+		time.Sleep(10 * time.Millisecond)
+		sub.Close()
+		close(closed)
+	}()
+
+	for _, ch := range []chan struct{}{closed, published} {
+		select {
+		case <-ch:
+		case <-time.After(time.Second):
+			s.Require().FailNow("closing a blocked subscriber must not deadlock")
+		}
+	}
+
+	_, err = s.broker.Subscribe("orders.*", s.bufSize)
+	s.Require().NoError(err, "broker must accept subscribers after the close")
+}
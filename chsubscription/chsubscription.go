@@ -4,64 +4,346 @@ import (
 	"context"
 	"slices"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
+// GroupStats describes the delivery of values to all the subscribers
+// of a ChSubscription. Delivered and Dropped are counted since the
+// group was created, QueueDepth only covers the current subscribers.
+type GroupStats struct {
+	Stats
+	Subscribers int
+}
+
+// target is a consumer of the values notified to a group, either a
+// Subscription of the same type or an adapter such as the one
+// created by Map. now is the time of the Notify call, taken once for
+// all the targets.
+type target[ChT any] interface {
+	deliver(ctx context.Context, val ChT, now time.Time) (bool, uint64)
+	queueDepth() int
+	close()
+}
+
+type replayEntry[ChT any] struct {
+	val ChT
+	at  time.Time
+}
+
+// ChSubscription broadcasts values to a group of subscribers. The
+// list of subscribers is an immutable snapshot replaced on every
+// Subscribe and Unsubscribe, so Notify reads it without locking and
+// publishers neither contend with each other nor block subscribers
+// from joining or leaving. Only groups with a replay serialize
+// Notify calls briefly to keep the replay in order.
 type ChSubscription[ChT any] struct {
+	// mu serializes the changes of items and the access to replay.
 	mu    sync.Mutex
-	items []chan ChT
+	items atomic.Pointer[[]target[ChT]]
+
+	replay       []replayEntry[ChT]
+	replaySize   int
+	replayMaxAge time.Duration
+
+	delivered    atomic.Uint64
+	dropped      atomic.Uint64
+	lastDelivery atomic.Int64
 }
 
-func New[ChT any]() *ChSubscription[ChT] {
-	return &ChSubscription[ChT]{
-		mu:    sync.Mutex{},
-		items: make([]chan ChT, 0, 3),
+func New[ChT any](opts ...Option) *ChSubscription[ChT] {
+	c := &config{
+		replaySize:   0,
+		replayMaxAge: 0,
+	}
+
+	for _, opt := range opts {
+		opt(c)
 	}
+
+	s := &ChSubscription[ChT]{
+		mu:           sync.Mutex{},
+		replay:       make([]replayEntry[ChT], 0, max(c.replaySize, 0)),
+		replaySize:   c.replaySize,
+		replayMaxAge: c.replayMaxAge,
+	}
+
+	s.items.Store(&[]target[ChT]{})
+
+	return s
 }
 
-func (s *ChSubscription[ChT]) Subscribe(bufSize int) <-chan ChT {
-	ch := make(chan ChT, bufSize)
+// snapshot returns the current list of subscribers, which must not
+// be modified.
+func (s *ChSubscription[ChT]) snapshot() []target[ChT] {
+	return *s.items.Load()
+}
 
-	s.mu.Lock()
-	s.items = append(s.items, ch)
-	s.mu.Unlock()
+// Subscribe adds a subscriber with a buffer of bufSize values. By
+// default a value is dropped when the buffer is full, this can be
+// changed with the DropOldest, Block and BlockWithTimeout options.
+func (s *ChSubscription[ChT]) Subscribe(bufSize int, opts ...SubscribeOption) *Subscription[ChT] {
+	return s.SubscribeFunc(bufSize, nil, opts...)
+}
+
+// SubscribeFunc is like Subscribe, but only delivers the values for
+// which filter returns true, a nil filter accepts every value. The
+// filter runs in Notify, before the
+// value takes space in the buffer, so it must be fast and safe for
+// concurrent use.
+func (s *ChSubscription[ChT]) SubscribeFunc(bufSize int, filter func(ChT) bool, opts ...SubscribeOption) *Subscription[ChT] {
+	sub := newSubscription[ChT](bufSize, opts...)
+	sub.filter = filter
+	sub.detach = func() {
+		s.remove(sub)
+	}
+
+	s.add(sub)
 
-	return ch
+	return sub
 }
 
-func (s *ChSubscription[ChT]) Notify(ctx context.Context, val ChT) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+// SubscribeContext is like Subscribe, but the subscription is closed
+// as soon as ctx is done, so it cannot outlive the work it was
+// created for.
+func (s *ChSubscription[ChT]) SubscribeContext(ctx context.Context, bufSize int, opts ...SubscribeOption) *Subscription[ChT] {
+	sub := s.Subscribe(bufSize, opts...)
 
-	for _, item := range s.items {
+	go func() {
 		select {
 		case <-ctx.Done():
-			return
-		case item <- val:
-		default:
+			sub.Close()
+		case <-sub.done:
 		}
+	}()
+
+	return sub
+}
+
+// Map subscribes to s and delivers the values converted by fn. Values
+// for which fn returns false are skipped, so fn can filter and
+// transform at once. Like the filter of SubscribeFunc, fn runs in
+// Notify.
+func Map[ChT, U any](s *ChSubscription[ChT], bufSize int, fn func(ChT) (U, bool), opts ...SubscribeOption) *Subscription[U] {
+	sub := newSubscription[U](bufSize, opts...)
+	adapter := &mapTarget[ChT, U]{
+		fn:  fn,
+		sub: sub,
+	}
+
+	sub.detach = func() {
+		s.remove(adapter)
 	}
+
+	s.add(adapter)
+
+	return sub
 }
 
-func (s *ChSubscription[ChT]) Unsubscribe(item <-chan ChT) {
+// add appends item to the group after replaying the remembered
+// values to it. Replayed values never block: the ones that do not fit
+// into the buffer are handled by the overflow policy as if the
+// context passed to Notify was done, so the buffer should be at
+// least as large as the replay.
+func (s *ChSubscription[ChT]) add(item target[ChT]) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	for idx, i := range s.items {
-		if i == item {
-			close(i)
-			s.items = slices.Delete(s.items, idx, idx+1)
+	now := time.Now()
+	s.pruneReplay(now)
+
+	if len(s.replay) > 0 {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		reached := 0
+		var dropped uint64
+		for _, entry := range s.replay {
+			ok, n := item.deliver(ctx, entry.val, now)
+			if ok {
+				reached++
+			}
+
+			dropped += n
+		}
+
+		s.record(reached, dropped, now)
+	}
+
+	items := slices.Clone(s.snapshot())
+	items = append(items, item)
+	s.items.Store(&items)
+}
+
+// pruneReplay drops the remembered values older than the max age,
+// the caller must hold s.mu.
+func (s *ChSubscription[ChT]) pruneReplay(now time.Time) {
+	if s.replayMaxAge <= 0 {
+		return
+	}
+
+	idx := 0
+	for idx < len(s.replay) && now.Sub(s.replay[idx].at) > s.replayMaxAge {
+		idx++
+	}
+
+	s.replay = slices.Delete(s.replay, 0, idx)
+}
+
+// remember adds val to the replay, the caller must hold s.mu.
+func (s *ChSubscription[ChT]) remember(val ChT, now time.Time) {
+	if s.replaySize <= 0 {
+		return
+	}
+
+	if len(s.replay) == s.replaySize {
+		s.replay = slices.Delete(s.replay, 0, 1)
+	}
+
+	s.replay = append(s.replay, replayEntry[ChT]{val: val, at: now})
+	s.pruneReplay(now)
+}
+
+// ResetReplay forgets the remembered values, so that new subscribers
+// only receive live values until the next Notify.
+func (s *ChSubscription[ChT]) ResetReplay() {
+	s.mu.Lock()
+	s.replay = s.replay[:0]
+	s.mu.Unlock()
+}
+
+// Notify sends val to every subscriber. It stops as soon as ctx is
+// done, the subscribers that have not been reached yet miss val. The
+// value is remembered for replay even if ctx is done.
+func (s *ChSubscription[ChT]) Notify(ctx context.Context, val ChT) {
+	s.notify(ctx, val)
+}
+
+// notify is Notify, it returns the number of subscribers val has
+// been delivered to.
+func (s *ChSubscription[ChT]) notify(ctx context.Context, val ChT) int {
+	now := time.Now()
+
+	var items []target[ChT]
+	if s.replaySize > 0 {
+		// The value must be remembered and the snapshot taken at once,
+		// otherwise a concurrent Subscribe could get it twice or miss
+		// it.
+		s.mu.Lock()
+		s.remember(val, now)
+		items = s.snapshot()
+		s.mu.Unlock()
+	} else {
+		items = s.snapshot()
+	}
+
+	reached := 0
+	var dropped uint64
+	for _, item := range items {
+		if ctx.Err() != nil {
 			break
 		}
+
+		ok, n := item.deliver(ctx, val, now)
+		if ok {
+			reached++
+		}
+
+		dropped += n
+	}
+
+	s.record(reached, dropped, now)
+
+	return reached
+}
+
+// record adds the results of a Notify or a replay to the group
+// counters. They are shared by all the publishers, so they are
+// updated once per call rather than once per subscriber.
+func (s *ChSubscription[ChT]) record(delivered int, dropped uint64, now time.Time) {
+	if delivered > 0 {
+		s.delivered.Add(uint64(delivered))
+		s.lastDelivery.Store(now.UnixNano())
+	}
+
+	if dropped > 0 {
+		s.dropped.Add(dropped)
+	}
+}
+
+// Unsubscribe removes sub from the group and closes its channel, it
+// is the same as calling sub.Close.
+func (s *ChSubscription[ChT]) Unsubscribe(sub *Subscription[ChT]) {
+	sub.Close()
+}
+
+func (s *ChSubscription[ChT]) remove(item target[ChT]) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	idx := slices.Index(s.snapshot(), item)
+	if idx < 0 {
+		return
 	}
+
+	items := slices.Delete(slices.Clone(s.snapshot()), idx, idx+1)
+	s.items.Store(&items)
+}
+
+// Stats returns the delivery statistics of the whole group.
+func (s *ChSubscription[ChT]) Stats() GroupStats {
+	items := s.snapshot()
+
+	stats := GroupStats{
+		Stats: Stats{
+			Delivered: s.delivered.Load(),
+			Dropped:   s.dropped.Load(),
+		},
+		Subscribers: len(items),
+	}
+
+	lastDelivery := s.lastDelivery.Load()
+	if lastDelivery != 0 {
+		stats.LastDelivery = time.Unix(0, lastDelivery)
+	}
+
+	for _, item := range items {
+		stats.QueueDepth += item.queueDepth()
+	}
+
+	return stats
 }
 
 func (s *ChSubscription[ChT]) Close() {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	for _, i := range s.items {
-		close(i)
+	items := s.snapshot()
+	s.items.Store(&[]target[ChT]{})
+
+	for _, i := range items {
+		i.close()
+	}
+}
+
+type mapTarget[ChT, U any] struct {
+	fn  func(ChT) (U, bool)
+	sub *Subscription[U]
+}
+
+func (t *mapTarget[ChT, U]) deliver(ctx context.Context, val ChT, now time.Time) (bool, uint64) {
+	mapped, ok := t.fn(val)
+	if !ok {
+		return false, 0
 	}
 
-	s.items = nil
+	return t.sub.deliver(ctx, mapped, now)
+}
+
+func (t *mapTarget[ChT, U]) queueDepth() int {
+	return t.sub.queueDepth()
+}
+
+func (t *mapTarget[ChT, U]) close() {
+	t.sub.close()
 }
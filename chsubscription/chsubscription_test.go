@@ -2,7 +2,9 @@ package chsubscription
 
 import (
 	"context"
+	"strconv"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/suite"
 )
@@ -31,15 +33,15 @@ func (s *testChSubscription) TearDownSuite() {
 }
 
 func (s *testChSubscription) TestSubscribe() {
-	ch := s.chsub.Subscribe(s.bufSize)
+	sub := s.chsub.Subscribe(s.bufSize)
 
 	errMsg := "channel must be non-nil "
-	s.Require().NotNil(ch, errMsg)
+	s.Require().NotNil(sub.C(), errMsg)
 }
 
 func (s *testChSubscription) TestNotify() {
-	ch1 := s.chsub.Subscribe(s.bufSize)
-	ch2 := s.chsub.Subscribe(s.bufSize)
+	ch1 := s.chsub.Subscribe(s.bufSize).C()
+	ch2 := s.chsub.Subscribe(s.bufSize).C()
 
 	s.chsub.Notify(s.ctx, s.val)
 
@@ -62,12 +64,13 @@ func (s *testChSubscription) TestNotify() {
 }
 
 func (s *testChSubscription) TestUnsubscribe() {
-	ch := s.chsub.Subscribe(0)
+	sub := s.chsub.Subscribe(0)
+	ch := sub.C()
 
-	s.chsub.Unsubscribe(ch)
+	s.chsub.Unsubscribe(sub)
 
 	errMsg := "items must to be empty after unsubscribe"
-	s.Require().Len(s.chsub.items, 0, errMsg)
+	s.Require().Len(s.chsub.snapshot(), 0, errMsg)
 
 	_, closed := <-ch
 	errMsg = "channel must to be closed"
@@ -75,8 +78,8 @@ func (s *testChSubscription) TestUnsubscribe() {
 }
 
 func (s *testChSubscription) TestClose() {
-	ch1 := s.chsub.Subscribe(s.bufSize)
-	ch2 := s.chsub.Subscribe(s.bufSize)
+	ch1 := s.chsub.Subscribe(s.bufSize).C()
+	ch2 := s.chsub.Subscribe(s.bufSize).C()
 
 	s.chsub.Close()
 
@@ -88,3 +91,358 @@ func (s *testChSubscription) TestClose() {
 	errMsg = "channel ch2 must to be closed"
 	s.Require().False(closed, errMsg)
 }
+
+func (s *testChSubscription) TestDropNewest() {
+	ch := s.chsub.Subscribe(1).C()
+
+	s.chsub.Notify(s.ctx, 1)
+	s.chsub.Notify(s.ctx, 2)
+
+	errMsg := "subscriber must keep the oldest value"
+	s.Require().Equal(1, <-ch, errMsg)
+}
+
+func (s *testChSubscription) TestDropOldest() {
+	ch := s.chsub.Subscribe(2, DropOldest()).C()
+
+	s.chsub.Notify(s.ctx, 1)
+	s.chsub.Notify(s.ctx, 2)
+	s.chsub.Notify(s.ctx, 3)
+
+	errMsg := "subscriber must keep the newest values"
+	s.Require().Equal(2, <-ch, errMsg)
+	s.Require().Equal(3, <-ch, errMsg)
+}
+
+func (s *testChSubscription) TestDropOldestUnbuffered() {
+	sub := s.chsub.Subscribe(0, DropOldest())
+
+	done := make(chan struct{})
+	go func() {
+		s.chsub.Notify(s.ctx, s.val)
+		sub.Close()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		errMsg := "notify must not spin on an unbuffered subscriber"
+		s.Require().FailNow(errMsg)
+	}
+
+	errMsg := "value must be dropped without a reader"
+	s.Require().Equal(uint64(1), sub.Stats().Dropped, errMsg)
+}
+
+func (s *testChSubscription) TestBlock() {
+	ch := s.chsub.Subscribe(0, Block()).C()
+
+	go func() {
+		s.chsub.Notify(s.ctx, s.val)
+	}()
+
+	errMsg := "blocking subscriber must receive the value"
+	s.Require().Equal(s.val, <-ch, errMsg)
+
+	ctx, cancel := context.WithCancel(s.ctx)
+	cancel()
+
+	s.chsub.Notify(ctx, s.val)
+
+	select {
+	case <-ch:
+		s.Require().FailNow("notify must give up once the context is done")
+	default:
+	}
+}
+
+func (s *testChSubscription) TestBlockWithTimeout() {
+	ch := s.chsub.Subscribe(0, BlockWithTimeout(time.Millisecond)).C()
+
+	s.chsub.Notify(s.ctx, s.val)
+
+	select {
+	case <-ch:
+		s.Require().FailNow("value must be dropped after the timeout")
+	default:
+	}
+}
+
+func (s *testChSubscription) TestStats() {
+	sub1 := s.chsub.Subscribe(1)
+	sub2 := s.chsub.Subscribe(2)
+
+	s.chsub.Notify(s.ctx, 1)
+	s.chsub.Notify(s.ctx, 2)
+
+	stats := sub1.Stats()
+	s.Require().Equal(uint64(1), stats.Delivered, "one value must be delivered to sub1")
+	s.Require().Equal(uint64(1), stats.Dropped, "one value must be dropped for sub1")
+	s.Require().Equal(1, stats.QueueDepth, "one value must wait in sub1")
+	s.Require().False(stats.LastDelivery.IsZero(), "last delivery must be set")
+
+	stats = sub2.Stats()
+	s.Require().Equal(uint64(2), stats.Delivered, "two values must be delivered to sub2")
+	s.Require().Zero(stats.Dropped, "no values must be dropped for sub2")
+
+	groupStats := s.chsub.Stats()
+	s.Require().Equal(2, groupStats.Subscribers, "group must have two subscribers")
+	s.Require().Equal(uint64(3), groupStats.Delivered, "group must count all deliveries")
+	s.Require().Equal(uint64(1), groupStats.Dropped, "group must count all drops")
+	s.Require().Equal(3, groupStats.QueueDepth, "group must sum queue depths")
+	s.Require().Equal(stats.LastDelivery, groupStats.LastDelivery, "group and subscribers must share the time of the notify")
+
+	sub1.Close()
+	sub1.Close()
+
+	errMsg := "closed subscription must be removed from the group"
+	s.Require().Equal(1, s.chsub.Stats().Subscribers, errMsg)
+}
+
+func (s *testChSubscription) TestSubscribeFunc() {
+	even := func(val int) bool {
+		return val%2 == 0
+	}
+
+	sub := s.chsub.SubscribeFunc(1, even)
+
+	s.chsub.Notify(s.ctx, 1)
+	s.chsub.Notify(s.ctx, 2)
+
+	errMsg := "filtered values must not take space in the buffer"
+	s.Require().Equal(2, <-sub.C(), errMsg)
+	s.Require().Zero(sub.Stats().Dropped, errMsg)
+}
+
+func (s *testChSubscription) TestMap() {
+	format := func(val int) (string, bool) {
+		return strconv.Itoa(val), val > 0
+	}
+
+	sub := Map(s.chsub, s.bufSize, format)
+
+	s.chsub.Notify(s.ctx, -1)
+	s.chsub.Notify(s.ctx, s.val)
+
+	errMsg := "mapped subscription must receive converted values only"
+	s.Require().Equal(1, len(sub.C()), errMsg)
+	s.Require().Equal(strconv.Itoa(s.val), <-sub.C(), errMsg)
+
+	sub.Close()
+
+	errMsg = "mapped subscription must be removed from the group on close"
+	s.Require().Len(s.chsub.snapshot(), 0, errMsg)
+}
+
+func (s *testChSubscription) TestLastValue() {
+	chsub := New[int](WithLastValue())
+	defer chsub.Close()
+
+	chsub.Notify(s.ctx, 1)
+	chsub.Notify(s.ctx, 2)
+
+	sub := chsub.Subscribe(s.bufSize)
+	chsub.Notify(s.ctx, 3)
+
+	errMsg := "late subscriber must receive the last value before live values"
+	s.Require().Equal(2, <-sub.C(), errMsg)
+	s.Require().Equal(3, <-sub.C(), errMsg)
+
+	chsub.ResetReplay()
+
+	sub = chsub.Subscribe(s.bufSize)
+	errMsg = "reset must drop the last value"
+	s.Require().Zero(len(sub.C()), errMsg)
+}
+
+func (s *testChSubscription) TestReplay() {
+	chsub := New[int](WithReplay(2, time.Hour))
+	defer chsub.Close()
+
+	for val := range 5 {
+		chsub.Notify(s.ctx, val)
+	}
+
+	chsub.replay[0].at = time.Now().Add(-2 * time.Hour)
+
+	sub := chsub.Subscribe(s.bufSize)
+
+	errMsg := "late subscriber must only receive the fresh replayed values"
+	s.Require().Equal(1, len(sub.C()), errMsg)
+	s.Require().Equal(4, <-sub.C(), errMsg)
+}
+
+func (s *testChSubscription) TestReplayStats() {
+	chsub := New[int](WithReplay(3, time.Hour))
+	defer chsub.Close()
+
+	for val := range 3 {
+		chsub.Notify(s.ctx, val)
+	}
+
+	sub1 := chsub.Subscribe(2)
+	sub2 := chsub.Subscribe(3)
+
+	stats1, stats2 := sub1.Stats(), sub2.Stats()
+	s.Require().Equal(uint64(2), stats1.Delivered, "replayed values must be counted for sub1")
+	s.Require().Equal(uint64(1), stats1.Dropped, "replayed value without room must be dropped for sub1")
+
+	groupStats := chsub.Stats()
+	s.Require().Equal(stats1.Delivered+stats2.Delivered, groupStats.Delivered, "group must count the replayed values")
+	s.Require().Equal(stats1.Dropped+stats2.Dropped, groupStats.Dropped, "group must count the dropped replayed values")
+	s.Require().Equal(stats2.LastDelivery, groupStats.LastDelivery, "group must record the last replay")
+}
+
+func (s *testChSubscription) TestSubscribeContext() {
+	ctx, cancel := context.WithCancel(s.ctx)
+	sub := s.chsub.SubscribeContext(ctx, s.bufSize)
+
+	errMsg := "subscriber must be added"
+	s.Require().Equal(1, s.chsub.Stats().Subscribers, errMsg)
+
+	cancel()
+
+	select {
+	case _, ok := <-sub.C():
+		errMsg = "channel must be closed when the context is done"
+		s.Require().False(ok, errMsg)
+	case <-time.After(time.Second):
+		errMsg = "channel must be closed when the context is done"
+		s.Require().FailNow(errMsg)
+	}
+
+	errMsg = "subscriber must be removed when the context is done"
+	s.Require().Len(s.chsub.snapshot(), 0, errMsg)
+}
+
+func (s *testChSubscription) TestAll() {
+	sub := s.chsub.Subscribe(s.bufSize)
+
+	for val := range s.bufSize {
+		s.chsub.Notify(s.ctx, val)
+	}
+
+	sub.Close()
+
+	var vals []int
+	for val := range sub.All(s.ctx) {
+		vals = append(vals, val)
+	}
+
+	errMsg := "iterator must yield every value until the channel is closed"
+	s.Require().Equal([]int{0, 1, 2}, vals, errMsg)
+
+	ctx, cancel := context.WithCancel(s.ctx)
+	cancel()
+
+	sub = s.chsub.Subscribe(s.bufSize)
+	defer sub.Close()
+
+	for range sub.All(ctx) {
+		errMsg = "iterator must stop when the context is done"
+		s.Require().FailNow(errMsg)
+	}
+}
+
+func (s *testChSubscription) TestUnbounded() {
+	var highWater []int
+	sub := s.chsub.Subscribe(1, Unbounded(), HighWaterMark(5, func(depth int) {
+		highWater = append(highWater, depth)
+	}))
+
+	for val := range 10 {
+		s.chsub.Notify(s.ctx, val)
+	}
+
+	errMsg := "high-water callback must be called once the queue reaches the mark"
+	s.Require().NotEmpty(highWater, errMsg)
+	s.Require().Equal(5, highWater[0], errMsg)
+
+	for want := range 10 {
+		select {
+		case val := <-sub.C():
+			errMsg = "unbounded subscriber must receive every value in order"
+			s.Require().Equal(want, val, errMsg)
+		case <-time.After(time.Second):
+			errMsg = "unbounded subscriber must not lose values"
+			s.Require().FailNow(errMsg)
+		}
+	}
+
+	stats := sub.Stats()
+	errMsg = "unbounded subscriber must not drop values"
+	s.Require().Zero(stats.Dropped, errMsg)
+}
+
+func (s *testChSubscription) TestHardCap() {
+	sub := s.chsub.Subscribe(0, Unbounded(), HardCap(2))
+	defer sub.Close()
+
+	s.chsub.Notify(s.ctx, 1)
+	s.chsub.Notify(s.ctx, 2)
+
+	// The drain goroutine may hold one of the values, so the queue
+	// is full after at most two more.
+	for val := range 2 {
+		s.chsub.Notify(s.ctx, val)
+	}
+
+	errMsg := "values beyond the hard cap must be dropped"
+	s.Require().NotZero(sub.Stats().Dropped, errMsg)
+}
+
+func (s *testChSubscription) TestDebounce() {
+	sub := Debounce(s.chsub, s.bufSize, 20*time.Millisecond)
+	defer sub.Close()
+
+	for val := range 5 {
+		s.chsub.Notify(s.ctx, val)
+	}
+
+	select {
+	case val := <-sub.C():
+		errMsg := "debounced subscriber must receive the last value of the burst"
+		s.Require().Equal(4, val, errMsg)
+	case <-time.After(time.Second):
+		errMsg := "debounced subscriber must receive a value after the quiet period"
+		s.Require().FailNow(errMsg)
+	}
+
+	errMsg := "debounced subscriber must receive a single value per burst"
+	s.Require().Zero(len(sub.C()), errMsg)
+}
+
+func (s *testChSubscription) TestThrottle() {
+	sub := Throttle(s.chsub, s.bufSize, time.Hour)
+	defer sub.Close()
+
+	for val := range 5 {
+		s.chsub.Notify(s.ctx, val)
+	}
+
+	errMsg := "throttled subscriber must only receive the first value of the interval"
+	s.Require().Equal(1, len(sub.C()), errMsg)
+	s.Require().Equal(0, <-sub.C(), errMsg)
+}
+
+func (s *testChSubscription) TestBatch() {
+	sub := Batch(s.chsub, s.bufSize, 2, 20*time.Millisecond)
+	defer sub.Close()
+
+	for val := range 3 {
+		s.chsub.Notify(s.ctx, val)
+	}
+
+	errMsg := "full batch must be delivered right away"
+	s.Require().Equal([]int{0, 1}, <-sub.C(), errMsg)
+
+	select {
+	case batch := <-sub.C():
+		errMsg = "partial batch must be delivered after the wait"
+		s.Require().Equal([]int{2}, batch, errMsg)
+	case <-time.After(time.Second):
+		errMsg = "partial batch must be delivered after the wait"
+		s.Require().FailNow(errMsg)
+	}
+}
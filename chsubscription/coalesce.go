@@ -0,0 +1,238 @@
+package chsubscription
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Debounce subscribes to s and delivers a value only once no other
+// value has been notified for quiet, so a burst of values results in
+// a single delivery of the last one. A value still waiting for the
+// quiet period when the subscription is closed is lost.
+func Debounce[ChT any](s *ChSubscription[ChT], bufSize int, quiet time.Duration, opts ...SubscribeOption) *Subscription[ChT] {
+	sub := newSubscription[ChT](bufSize, opts...)
+	adapter := &debounceTarget[ChT]{
+		mu:    sync.Mutex{},
+		quiet: quiet,
+		timer: nil,
+		sub:   sub,
+	}
+
+	sub.detach = func() {
+		s.remove(adapter)
+	}
+
+	s.add(adapter)
+
+	return sub
+}
+
+// Throttle subscribes to s and delivers at most one value per
+// interval. The first value of a burst is delivered right away and
+// the following ones are skipped until interval has passed.
+func Throttle[ChT any](s *ChSubscription[ChT], bufSize int, interval time.Duration, opts ...SubscribeOption) *Subscription[ChT] {
+	sub := newSubscription[ChT](bufSize, opts...)
+	adapter := &throttleTarget[ChT]{
+		mu:       sync.Mutex{},
+		interval: interval,
+		last:     time.Time{},
+		sub:      sub,
+	}
+
+	sub.detach = func() {
+		s.remove(adapter)
+	}
+
+	s.add(adapter)
+
+	return sub
+}
+
+// Batch subscribes to s and delivers the values in batches of size,
+// or fewer once wait has passed since the first value of the batch.
+// A batch still being collected when the subscription is closed is
+// lost.
+func Batch[ChT any](s *ChSubscription[ChT], bufSize int, size int, wait time.Duration, opts ...SubscribeOption) *Subscription[[]ChT] {
+	sub := newSubscription[[]ChT](bufSize, opts...)
+	adapter := &batchTarget[ChT]{
+		mu:    sync.Mutex{},
+		size:  max(size, 1),
+		wait:  wait,
+		batch: nil,
+		timer: nil,
+		sub:   sub,
+	}
+
+	sub.detach = func() {
+		s.remove(adapter)
+	}
+
+	s.add(adapter)
+
+	return sub
+}
+
+// The timers of debounceTarget and batchTarget may fire while they
+// are being replaced, so every timer carries the generation it was
+// started for and does nothing once a newer one has been started.
+
+type debounceTarget[ChT any] struct {
+	mu         sync.Mutex
+	quiet      time.Duration
+	last       ChT
+	timer      *time.Timer
+	generation uint64
+	sub        *Subscription[ChT]
+}
+
+func (t *debounceTarget[ChT]) deliver(ctx context.Context, val ChT, now time.Time) (bool, uint64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.last = val
+
+	if t.timer != nil {
+		t.timer.Stop()
+	}
+
+	t.generation++
+	generation := t.generation
+
+	t.timer = time.AfterFunc(t.quiet, func() {
+		t.flush(generation)
+	})
+
+	return false, 0
+}
+
+func (t *debounceTarget[ChT]) flush(generation uint64) {
+	t.mu.Lock()
+	if generation != t.generation {
+		t.mu.Unlock()
+		return
+	}
+
+	val := t.last
+	t.timer = nil
+	t.mu.Unlock()
+
+	t.sub.send(context.Background(), val, time.Now())
+}
+
+func (t *debounceTarget[ChT]) queueDepth() int {
+	return t.sub.queueDepth()
+}
+
+func (t *debounceTarget[ChT]) close() {
+	t.mu.Lock()
+	if t.timer != nil {
+		t.timer.Stop()
+	}
+
+	t.generation++
+	t.mu.Unlock()
+
+	t.sub.close()
+}
+
+type throttleTarget[ChT any] struct {
+	mu       sync.Mutex
+	interval time.Duration
+	last     time.Time
+	sub      *Subscription[ChT]
+}
+
+func (t *throttleTarget[ChT]) deliver(ctx context.Context, val ChT, now time.Time) (bool, uint64) {
+	t.mu.Lock()
+	if !t.last.IsZero() && now.Sub(t.last) < t.interval {
+		t.mu.Unlock()
+		return false, 0
+	}
+
+	t.last = now
+	t.mu.Unlock()
+
+	return t.sub.deliver(ctx, val, now)
+}
+
+func (t *throttleTarget[ChT]) queueDepth() int {
+	return t.sub.queueDepth()
+}
+
+func (t *throttleTarget[ChT]) close() {
+	t.sub.close()
+}
+
+type batchTarget[ChT any] struct {
+	mu         sync.Mutex
+	size       int
+	wait       time.Duration
+	batch      []ChT
+	timer      *time.Timer
+	generation uint64
+	sub        *Subscription[[]ChT]
+}
+
+func (t *batchTarget[ChT]) deliver(ctx context.Context, val ChT, now time.Time) (bool, uint64) {
+	t.mu.Lock()
+	t.batch = append(t.batch, val)
+
+	if len(t.batch) < t.size {
+		if len(t.batch) == 1 {
+			generation := t.generation
+			t.timer = time.AfterFunc(t.wait, func() {
+				t.flush(generation)
+			})
+		}
+
+		t.mu.Unlock()
+		return false, 0
+	}
+
+	batch := t.take()
+	t.mu.Unlock()
+
+	return t.sub.deliver(ctx, batch, now)
+}
+
+// take returns the collected batch and starts a new one, the caller
+// must hold t.mu.
+func (t *batchTarget[ChT]) take() []ChT {
+	if t.timer != nil {
+		t.timer.Stop()
+		t.timer = nil
+	}
+
+	t.generation++
+
+	batch := t.batch
+	t.batch = nil
+
+	return batch
+}
+
+func (t *batchTarget[ChT]) flush(generation uint64) {
+	t.mu.Lock()
+	if generation != t.generation {
+		t.mu.Unlock()
+		return
+	}
+
+	batch := t.take()
+	t.mu.Unlock()
+
+	t.sub.send(context.Background(), batch, time.Now())
+}
+
+func (t *batchTarget[ChT]) queueDepth() int {
+	return t.sub.queueDepth()
+}
+
+func (t *batchTarget[ChT]) close() {
+	t.mu.Lock()
+	t.take()
+	t.mu.Unlock()
+
+	t.sub.close()
+}
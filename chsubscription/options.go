@@ -0,0 +1,133 @@
+package chsubscription
+
+import "time"
+
+type overflow int
+
+const (
+	overflowDropNewest overflow = iota
+	overflowDropOldest
+	overflowBlock
+	overflowBlockWithTimeout
+	overflowUnbounded
+)
+
+type subscribeConfig struct {
+	overflow    overflow
+	timeout     time.Duration
+	highWater   int
+	onHighWater func(depth int)
+	hardCap     int
+}
+
+type SubscribeOption func(*subscribeConfig)
+
+// DropNewest drops the value being sent when the buffer of the
+// subscriber is full. It is the default overflow policy.
+func DropNewest() SubscribeOption {
+	return func(c *subscribeConfig) {
+		c.overflow = overflowDropNewest
+	}
+}
+
+// DropOldest makes room for the value being sent by dropping the
+// oldest value in the buffer of the subscriber when it is full. A
+// subscriber without a buffer behaves as with DropNewest.
+func DropOldest() SubscribeOption {
+	return func(c *subscribeConfig) {
+		c.overflow = overflowDropOldest
+	}
+}
+
+// Block makes Notify wait until the subscriber has room for the
+// value, or until the context passed to Notify is done.
+func Block() SubscribeOption {
+	return func(c *subscribeConfig) {
+		c.overflow = overflowBlock
+	}
+}
+
+// BlockWithTimeout is like Block, but drops the value if the
+// subscriber has no room for it within timeout.
+func BlockWithTimeout(timeout time.Duration) SubscribeOption {
+	return func(c *subscribeConfig) {
+		c.overflow = overflowBlockWithTimeout
+		c.timeout = timeout
+	}
+}
+
+// Unbounded queues the values the subscriber has no room for instead
+// of dropping them or blocking Notify. The queue grows as needed and
+// a goroutine moves its values into the channel as the subscriber
+// reads it. Queued values count as delivered in the Stats, those
+// still queued when the subscription is closed are lost.
+func Unbounded() SubscribeOption {
+	return func(c *subscribeConfig) {
+		c.overflow = overflowUnbounded
+	}
+}
+
+// HighWaterMark calls fn every time the queue of an Unbounded
+// subscriber grows to mark values, which is useful to log or alert
+// about a slow subscriber. fn runs in Notify, so it must be fast.
+func HighWaterMark(mark int, fn func(depth int)) SubscribeOption {
+	return func(c *subscribeConfig) {
+		c.highWater = mark
+		c.onHighWater = fn
+	}
+}
+
+// HardCap limits the queue of an Unbounded subscriber to size values,
+// the values beyond it are dropped. Zero means no limit.
+func HardCap(size int) SubscribeOption {
+	return func(c *subscribeConfig) {
+		c.hardCap = size
+	}
+}
+
+type config struct {
+	replaySize   int
+	replayMaxAge time.Duration
+}
+
+type Option func(*config)
+
+// WithLastValue makes the group remember the last notified value and
+// deliver it to every new subscriber before the live values.
+func WithLastValue() Option {
+	return WithReplay(1, 0)
+}
+
+// WithReplay makes the group remember the last size notified values
+// and deliver them, in order, to every new subscriber before the
+// live values. Values older than maxAge are not replayed, a zero
+// maxAge keeps values until they are pushed out by newer ones.
+func WithReplay(size int, maxAge time.Duration) Option {
+	return func(c *config) {
+		c.replaySize = size
+		c.replayMaxAge = maxAge
+	}
+}
+
+type ackedConfig struct {
+	visibilityTimeout time.Duration
+	maxAttempts       int
+}
+
+type AckedOption func(*ackedConfig)
+
+// WithVisibilityTimeout sets how long a delivered message may stay
+// unacknowledged before it is delivered again.
+func WithVisibilityTimeout(timeout time.Duration) AckedOption {
+	return func(c *ackedConfig) {
+		c.visibilityTimeout = timeout
+	}
+}
+
+// WithMaxAttempts sets how many times a message is delivered before
+// it is moved to the dead-letter group, zero means no limit.
+func WithMaxAttempts(attempts int) AckedOption {
+	return func(c *ackedConfig) {
+		c.maxAttempts = attempts
+	}
+}
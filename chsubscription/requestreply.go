@@ -0,0 +1,170 @@
+package chsubscription
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+)
+
+var (
+	// ErrNoReply is returned by a responder that has no answer for a
+	// request, such as a shard that does not own the requested key.
+	ErrNoReply = errors.New("no reply")
+
+	// ErrNoResponders is returned by Request when no responder has
+	// answered the request.
+	ErrNoResponders = errors.New("no responders")
+)
+
+type request[Req any] struct {
+	ctx context.Context
+	id  uint64
+	val Req
+}
+
+type reply[Resp any] struct {
+	id  uint64
+	val Resp
+	err error
+}
+
+// RequestReply lets components ask a question and wait for the
+// answers of the responders registered with Respond. Requests are
+// broadcast to every responder and the replies are matched to their
+// request with a correlation ID, both through a ChSubscription.
+type RequestReply[Req, Resp any] struct {
+	requests *ChSubscription[request[Req]]
+	replies  *ChSubscription[reply[Resp]]
+	nextID   atomic.Uint64
+}
+
+func NewRequestReply[Req, Resp any]() *RequestReply[Req, Resp] {
+	return &RequestReply[Req, Resp]{
+		requests: New[request[Req]](),
+		replies:  New[reply[Resp]](),
+	}
+}
+
+// Responder is a handle to a responder registered with Respond.
+type Responder struct {
+	sub interface{ Close() }
+}
+
+// Close stops the responder from receiving new requests, the requests
+// it is handling are still answered.
+func (r *Responder) Close() {
+	r.sub.Close()
+}
+
+// Respond registers fn as a responder. Every request is handled in
+// its own goroutine with the context passed to Request, fn returns
+// ErrNoReply to leave the request to the other responders.
+func (rr *RequestReply[Req, Resp]) Respond(fn func(ctx context.Context, req Req) (Resp, error)) *Responder {
+	sub := rr.requests.Subscribe(1, Block())
+
+	go func() {
+		for req := range sub.C() {
+			go func() {
+				val, err := fn(req.ctx, req.val)
+				rr.replies.Notify(context.Background(), reply[Resp]{
+					id:  req.id,
+					val: val,
+					err: err,
+				})
+			}()
+		}
+	}()
+
+	return &Responder{sub: sub}
+}
+
+// send broadcasts req to the responders and returns the subscription
+// to its replies along with the number of responders it has reached.
+// The subscription is unbounded since the number of replies is only
+// known once the request has been sent.
+func (rr *RequestReply[Req, Resp]) send(ctx context.Context, req Req) (*Subscription[reply[Resp]], int) {
+	id := rr.nextID.Add(1)
+
+	replies := rr.replies.SubscribeFunc(1, func(r reply[Resp]) bool {
+		return r.id == id
+	}, Unbounded())
+
+	responders := rr.requests.notify(ctx, request[Req]{
+		ctx: ctx,
+		id:  id,
+		val: req,
+	})
+
+	return replies, responders
+}
+
+// Request sends req to the responders and returns the first reply,
+// whether it is a value or an error. It returns ErrNoResponders if
+// every responder has returned ErrNoReply, and the error of ctx if it
+// is done before a reply arrives.
+func (rr *RequestReply[Req, Resp]) Request(ctx context.Context, req Req) (Resp, error) {
+	var zero Resp
+
+	replies, responders := rr.send(ctx, req)
+	defer replies.Close()
+
+	for range responders {
+		select {
+		case <-ctx.Done():
+			return zero, ctx.Err()
+		case r, ok := <-replies.C():
+			if !ok {
+				return zero, ErrNoResponders
+			}
+
+			if errors.Is(r.err, ErrNoReply) {
+				continue
+			}
+
+			return r.val, r.err
+		}
+	}
+
+	return zero, ErrNoResponders
+}
+
+// Gather sends req to the responders and collects the replies of all
+// the responders the request has reached. It returns
+// the values along with the errors of the responders joined together,
+// and stops early with the values collected so far if ctx is done.
+func (rr *RequestReply[Req, Resp]) Gather(ctx context.Context, req Req) ([]Resp, error) {
+	replies, responders := rr.send(ctx, req)
+	defer replies.Close()
+
+	vals := make([]Resp, 0, responders)
+	var errs []error
+
+	for range responders {
+		select {
+		case <-ctx.Done():
+			errs = append(errs, ctx.Err())
+			return vals, errors.Join(errs...)
+		case r, ok := <-replies.C():
+			if !ok {
+				return vals, errors.Join(errs...)
+			}
+
+			switch {
+			case errors.Is(r.err, ErrNoReply):
+			case r.err != nil:
+				errs = append(errs, r.err)
+			default:
+				vals = append(vals, r.val)
+			}
+		}
+	}
+
+	return vals, errors.Join(errs...)
+}
+
+// Close closes the responders, pending requests end with the replies
+// received so far.
+func (rr *RequestReply[Req, Resp]) Close() {
+	rr.requests.Close()
+	rr.replies.Close()
+}
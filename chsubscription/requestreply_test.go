@@ -0,0 +1,128 @@
+package chsubscription
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type testRequestReply struct {
+	suite.Suite
+	ctx context.Context
+	rr  *RequestReply[string, int]
+}
+
+func TestRequestReplySuite(t *testing.T) {
+	suite.Run(t, new(testRequestReply))
+}
+
+func (s *testRequestReply) SetupTest() {
+	s.ctx = context.Background()
+	s.rr = NewRequestReply[string, int]()
+}
+
+func (s *testRequestReply) TearDownTest() {
+	s.rr.Close()
+}
+
+// shard registers a responder that owns the keys listed in keys.
+func (s *testRequestReply) shard(id int, keys ...string) *Responder {
+	return s.rr.Respond(func(ctx context.Context, key string) (int, error) {
+		for _, k := range keys {
+			if k == key {
+				return id, nil
+			}
+		}
+
+		return 0, ErrNoReply
+	})
+}
+
+func (s *testRequestReply) TestRequest() {
+	s.shard(1, "a")
+	s.shard(2, "b")
+
+	owner, err := s.rr.Request(s.ctx, "b")
+	s.Require().NoError(err, "request must be answered")
+	s.Require().Equal(2, owner, "owner of the key must answer")
+
+	_, err = s.rr.Request(s.ctx, "c")
+	s.Require().ErrorIs(err, ErrNoResponders, "request nobody answers must fail")
+}
+
+func (s *testRequestReply) TestRequestTimeout() {
+	s.rr.Respond(func(ctx context.Context, key string) (int, error) {
+		<-ctx.Done()
+		return 0, ctx.Err()
+	})
+
+	ctx, cancel := context.WithTimeout(s.ctx, 10*time.Millisecond)
+	defer cancel()
+
+	_, err := s.rr.Request(ctx, "a")
+	s.Require().ErrorIs(err, context.DeadlineExceeded, "request must end with its context")
+}
+
+func (s *testRequestReply) TestGather() {
+	errTest := errors.New("test error")
+
+	s.shard(1, "a")
+	s.shard(2, "a")
+	s.shard(3, "b")
+	s.rr.Respond(func(ctx context.Context, key string) (int, error) {
+		return 0, errTest
+	})
+
+	owners, err := s.rr.Gather(s.ctx, "a")
+	s.Require().ErrorIs(err, errTest, "errors of the responders must be returned")
+	s.Require().ElementsMatch([]int{1, 2}, owners, "replies of all the responders must be gathered")
+}
+
+func (s *testRequestReply) TestResponderClose() {
+	s.shard(1, "a").Close()
+
+	_, err := s.rr.Request(s.ctx, "a")
+	s.Require().ErrorIs(err, ErrNoResponders, "closed responder must not answer")
+}
+
+func (s *testRequestReply) TestUnreachedResponder() {
+	s.shard(1, "a")
+
+	// A subscriber the request cannot reach must not be waited for.
+	stuck := s.rr.requests.Subscribe(0)
+	defer stuck.Close()
+
+	type result struct {
+		owners []int
+		err    error
+	}
+
+	results := make(chan result, 1)
+	go func() {
+		owners, err := s.rr.Gather(s.ctx, "a")
+		results <- result{owners: owners, err: err}
+	}()
+
+	select {
+	case r := <-results:
+		s.Require().NoError(r.err, "gather must succeed")
+		s.Require().Equal([]int{1}, r.owners, "reached responder must answer")
+	case <-time.After(time.Second):
+		s.Require().FailNow("gather must only wait for the responders it has reached")
+	}
+
+	go func() {
+		_, err := s.rr.Request(s.ctx, "b")
+		results <- result{err: err}
+	}()
+
+	select {
+	case r := <-results:
+		s.Require().ErrorIs(r.err, ErrNoResponders, "request must not wait for unreached responders")
+	case <-time.After(time.Second):
+		s.Require().FailNow("request must only wait for the responders it has reached")
+	}
+}
@@ -0,0 +1,46 @@
+package chsubscription
+
+// ring is a FIFO queue backed by a circular buffer that grows when it
+// is full. It is not safe for concurrent use.
+type ring[T any] struct {
+	buf  []T
+	head int
+	size int
+}
+
+func (r *ring[T]) len() int {
+	return r.size
+}
+
+func (r *ring[T]) push(val T) {
+	if r.size == len(r.buf) {
+		r.grow()
+	}
+
+	r.buf[(r.head+r.size)%len(r.buf)] = val
+	r.size++
+}
+
+func (r *ring[T]) pop() (T, bool) {
+	var zero T
+	if r.size == 0 {
+		return zero, false
+	}
+
+	val := r.buf[r.head]
+	r.buf[r.head] = zero
+	r.head = (r.head + 1) % len(r.buf)
+	r.size--
+
+	return val, true
+}
+
+func (r *ring[T]) grow() {
+	buf := make([]T, max(2*len(r.buf), 8))
+
+	n := copy(buf, r.buf[r.head:])
+	copy(buf[n:], r.buf[:r.head])
+
+	r.buf = buf
+	r.head = 0
+}
@@ -0,0 +1,310 @@
+package chsubscription
+
+import (
+	"context"
+	"iter"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Stats describes the delivery of values to a subscriber.
+type Stats struct {
+	Delivered    uint64    // values put into the channel, or into the queue of an Unbounded subscriber
+	Dropped      uint64    // values lost because of the overflow policy
+	QueueDepth   int       // values waiting in the channel and the queue
+	LastDelivery time.Time // zero if nothing has been delivered yet
+}
+
+// Subscription is a handle to a single subscriber of a
+// ChSubscription. Values are received from C, and Close removes the
+// subscriber and closes the channel.
+type Subscription[ChT any] struct {
+	ch       chan ChT
+	overflow overflow
+	timeout  time.Duration
+	filter   func(ChT) bool
+
+	// The queue of an Unbounded subscriber, drained into ch by the
+	// drain goroutine.
+	queueMu     sync.Mutex
+	queue       ring[ChT]
+	highWater   int
+	onHighWater func(depth int)
+	hardCap     int
+	wake        chan struct{}
+	stopped     chan struct{}
+
+	delivered    atomic.Uint64
+	dropped      atomic.Uint64
+	lastDelivery atomic.Int64
+
+	// detach removes the subscription from its group, it is set by
+	// the group when the subscription is added.
+	detach func()
+
+	// Publishers may still hold the subscription in a snapshot after
+	// it has been closed. They send under the read lock and give up
+	// once closed is set, close takes the write lock after closing
+	// done, which wakes up the publishers blocked on a full buffer.
+	mu        sync.RWMutex
+	closed    bool
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func newSubscription[ChT any](bufSize int, opts ...SubscribeOption) *Subscription[ChT] {
+	c := &subscribeConfig{
+		overflow: overflowDropNewest,
+		timeout:  0,
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	s := &Subscription[ChT]{
+		ch:          make(chan ChT, bufSize),
+		overflow:    c.overflow,
+		timeout:     c.timeout,
+		filter:      nil,
+		queueMu:     sync.Mutex{},
+		queue:       ring[ChT]{},
+		highWater:   c.highWater,
+		onHighWater: c.onHighWater,
+		hardCap:     c.hardCap,
+		wake:        nil,
+		stopped:     nil,
+		detach:      func() {},
+		mu:          sync.RWMutex{},
+		closed:      false,
+		done:        make(chan struct{}),
+		closeOnce:   sync.Once{},
+	}
+
+	if s.overflow == overflowUnbounded {
+		s.wake = make(chan struct{}, 1)
+		s.stopped = make(chan struct{})
+
+		go s.drain()
+	}
+
+	return s
+}
+
+// C returns the channel the values are delivered to. It is closed
+// when the subscription or its group is closed.
+func (s *Subscription[ChT]) C() <-chan ChT {
+	return s.ch
+}
+
+// Close removes the subscription from its group and closes the
+// channel. It is safe to call Close more than once.
+func (s *Subscription[ChT]) Close() {
+	s.detach()
+	s.close()
+}
+
+// All returns an iterator over the values of the subscription, which
+// ends when the channel is closed or ctx is done. Breaking out of the
+// loop does not close the subscription.
+func (s *Subscription[ChT]) All(ctx context.Context) iter.Seq[ChT] {
+	return func(yield func(ChT) bool) {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case val, ok := <-s.ch:
+				if !ok || !yield(val) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Stats returns the delivery statistics of the subscription.
+func (s *Subscription[ChT]) Stats() Stats {
+	stats := Stats{
+		Delivered:  s.delivered.Load(),
+		Dropped:    s.dropped.Load(),
+		QueueDepth: s.queueDepth(),
+	}
+
+	lastDelivery := s.lastDelivery.Load()
+	if lastDelivery != 0 {
+		stats.LastDelivery = time.Unix(0, lastDelivery)
+	}
+
+	return stats
+}
+
+func (s *Subscription[ChT]) queueDepth() int {
+	s.queueMu.Lock()
+	defer s.queueMu.Unlock()
+
+	return len(s.ch) + s.queue.len()
+}
+
+// deliver sends val unless the filter of the subscription rejects
+// it, see send for the results.
+func (s *Subscription[ChT]) deliver(ctx context.Context, val ChT, now time.Time) (bool, uint64) {
+	if s.filter != nil && !s.filter(val) {
+		return false, 0
+	}
+
+	return s.send(ctx, val, now)
+}
+
+func (s *Subscription[ChT]) close() {
+	s.closeOnce.Do(func() {
+		close(s.done)
+
+		if s.stopped != nil {
+			<-s.stopped
+		}
+
+		s.mu.Lock()
+		s.closed = true
+		close(s.ch)
+		s.mu.Unlock()
+	})
+}
+
+// send delivers val according to the overflow policy of the
+// subscription. It reports whether val was delivered and how many
+// values were dropped along the way, now is recorded as the time of
+// the delivery.
+func (s *Subscription[ChT]) send(ctx context.Context, val ChT, now time.Time) (bool, uint64) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.closed {
+		return false, 0
+	}
+
+	delivered, dropped := s.trySend(ctx, val)
+
+	if dropped > 0 {
+		s.dropped.Add(dropped)
+	}
+
+	if delivered {
+		s.delivered.Add(1)
+		s.lastDelivery.Store(now.UnixNano())
+	}
+
+	return delivered, dropped
+}
+
+func (s *Subscription[ChT]) trySend(ctx context.Context, val ChT) (bool, uint64) {
+	if s.overflow == overflowUnbounded {
+		return s.enqueue(val)
+	}
+
+	select {
+	case s.ch <- val:
+		return true, 0
+	default:
+	}
+
+	switch s.overflow {
+	case overflowDropOldest:
+		// An unbuffered channel has no oldest value to drop.
+		if cap(s.ch) == 0 {
+			return false, 1
+		}
+
+		var dropped uint64
+		for {
+			select {
+			case s.ch <- val:
+				return true, dropped
+			default:
+			}
+
+			select {
+			case <-s.ch:
+				dropped++
+			default:
+			}
+		}
+	case overflowBlock:
+		select {
+		case <-ctx.Done():
+			return false, 1
+		case <-s.done:
+			return false, 0
+		case s.ch <- val:
+			return true, 0
+		}
+	case overflowBlockWithTimeout:
+		timer := time.NewTimer(s.timeout)
+		defer timer.Stop()
+
+		select {
+		case <-ctx.Done():
+			return false, 1
+		case <-timer.C:
+			return false, 1
+		case <-s.done:
+			return false, 0
+		case s.ch <- val:
+			return true, 0
+		}
+	default:
+		return false, 1
+	}
+}
+
+// enqueue adds val to the queue of an Unbounded subscriber, a queued
+// value counts as delivered.
+func (s *Subscription[ChT]) enqueue(val ChT) (bool, uint64) {
+	s.queueMu.Lock()
+	if s.hardCap > 0 && s.queue.len() >= s.hardCap {
+		s.queueMu.Unlock()
+		return false, 1
+	}
+
+	s.queue.push(val)
+	depth := s.queue.len()
+	s.queueMu.Unlock()
+
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+
+	if s.onHighWater != nil && depth == s.highWater {
+		s.onHighWater(depth)
+	}
+
+	return true, 0
+}
+
+// drain moves the queued values of an Unbounded subscriber into the
+// channel until the subscription is closed.
+func (s *Subscription[ChT]) drain() {
+	defer close(s.stopped)
+
+	for {
+		s.queueMu.Lock()
+		val, ok := s.queue.pop()
+		s.queueMu.Unlock()
+
+		if !ok {
+			select {
+			case <-s.done:
+				return
+			case <-s.wake:
+				continue
+			}
+		}
+
+		select {
+		case <-s.done:
+			return
+		case s.ch <- val:
+		}
+	}
+}
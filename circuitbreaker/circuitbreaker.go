@@ -0,0 +1,326 @@
+package circuitbreaker
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/mtchuikov/pkg/backoff"
+	"github.com/mtchuikov/pkg/chsubscription"
+)
+
+const (
+	DefaultConsecutiveFailures = 5
+	DefaultHalfOpenProbes      = 1
+	DefaultCooldownInterval    = 1 * time.Second
+	DefaultCooldownMax         = 1 * time.Minute
+
+	subscriptionBufSize = 3
+)
+
+var (
+	ErrOpen          = errors.New("circuit breaker is open")
+	ErrTooManyProbes = errors.New("too many half-open probes")
+)
+
+type State int
+
+const (
+	StateClosed State = iota
+	StateOpen
+	StateHalfOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// StateChange is sent to subscribers every time the breaker moves
+// from one state to another.
+type StateChange struct {
+	From State
+	To   State
+	At   time.Time
+}
+
+// window keeps the outcomes of the last calls in a ring buffer.
+type window struct {
+	outcomes []bool
+	pos      int
+	calls    int
+	failures int
+}
+
+func newWindow(size int) *window {
+	return &window{outcomes: make([]bool, max(size, 1))}
+}
+
+func (w *window) record(failed bool) {
+	if w.calls == len(w.outcomes) {
+		if w.outcomes[w.pos] {
+			w.failures--
+		}
+	} else {
+		w.calls++
+	}
+
+	w.outcomes[w.pos] = failed
+	if failed {
+		w.failures++
+	}
+
+	w.pos = (w.pos + 1) % len(w.outcomes)
+}
+
+func (w *window) reset() {
+	clear(w.outcomes)
+	w.pos = 0
+	w.calls = 0
+	w.failures = 0
+}
+
+// CircuitBreaker stops calling a failing dependency for a while so
+// that it has time to recover. It starts closed and lets every call
+// through. Once the failure thresholds are reached it opens and
+// rejects calls with ErrOpen for a cool-down computed by a backoff
+// policy, then lets a limited number of probes through in the
+// half-open state to decide whether to close again or to reopen
+// with a longer cool-down. It is safe for concurrent use.
+type CircuitBreaker struct {
+	mu sync.Mutex
+
+	state      State
+	generation uint64
+
+	openUntil    time.Time
+	lastCooldown time.Duration
+
+	maxConsecutiveFailures int
+	consecutiveFailures    int
+
+	failureRate float64
+	minCalls    int
+	window      *window
+
+	// probes counts the calls let through since the breaker became
+	// half-open, including the finished ones.
+	maxProbes      int
+	probes         int
+	probeSuccesses int
+
+	cooldown  backoff.Policy
+	isFailure func(error) bool
+	clock     backoff.Clock
+
+	chsub *chsubscription.ChSubscription[StateChange]
+}
+
+func New(opts ...Option) *CircuitBreaker {
+	cooldown := backoff.New()
+	cooldown.Interval = DefaultCooldownInterval
+	cooldown.Max = DefaultCooldownMax
+
+	cb := &CircuitBreaker{
+		mu:                     sync.Mutex{},
+		state:                  StateClosed,
+		maxConsecutiveFailures: DefaultConsecutiveFailures,
+		window:                 nil,
+		maxProbes:              DefaultHalfOpenProbes,
+		cooldown:               cooldown,
+		isFailure:              isFailure,
+		clock:                  backoff.SystemClock,
+		chsub:                  chsubscription.New[StateChange](),
+	}
+
+	for _, opt := range opts {
+		opt(cb)
+	}
+
+	return cb
+}
+
+func isFailure(err error) bool {
+	return err != nil && !errors.Is(err, context.Canceled)
+}
+
+// State returns the current state of the breaker.
+func (cb *CircuitBreaker) State() State {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	cb.refreshState()
+
+	return cb.state
+}
+
+// Allow reports whether a call may go through. On success the caller
+// must make the call and pass its error to done, results reported
+// after the breaker has changed state are ignored.
+func (cb *CircuitBreaker) Allow() (done func(err error), err error) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	cb.refreshState()
+
+	switch cb.state {
+	case StateOpen:
+		return nil, ErrOpen
+	case StateHalfOpen:
+		if cb.probes >= cb.maxProbes {
+			return nil, ErrTooManyProbes
+		}
+
+		cb.probes++
+	}
+
+	generation := cb.generation
+	var once sync.Once
+
+	done = func(err error) {
+		once.Do(func() {
+			cb.record(generation, cb.isFailure(err))
+		})
+	}
+
+	return done, nil
+}
+
+// Execute calls fn if the breaker allows it and records the result.
+func (cb *CircuitBreaker) Execute(ctx context.Context, fn func(context.Context) error) error {
+	done, err := cb.Allow()
+	if err != nil {
+		return err
+	}
+
+	err = fn(ctx)
+	done(err)
+
+	return err
+}
+
+// Subscribe returns a subscription that receives every state change
+// of the breaker.
+func (cb *CircuitBreaker) Subscribe() *chsubscription.Subscription[StateChange] {
+	return cb.chsub.Subscribe(subscriptionBufSize)
+}
+
+func (cb *CircuitBreaker) Unsubscribe(sub *chsubscription.Subscription[StateChange]) {
+	cb.chsub.Unsubscribe(sub)
+}
+
+// Close closes all the subscriptions, the breaker itself keeps
+// working.
+func (cb *CircuitBreaker) Close() {
+	cb.chsub.Close()
+}
+
+func (cb *CircuitBreaker) record(generation uint64, failed bool) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if generation != cb.generation {
+		return
+	}
+
+	switch cb.state {
+	case StateClosed:
+		if failed {
+			cb.consecutiveFailures++
+		} else {
+			cb.consecutiveFailures = 0
+		}
+
+		if cb.window != nil {
+			cb.window.record(failed)
+		}
+
+		if cb.shouldTrip() {
+			cb.trip()
+		}
+	case StateHalfOpen:
+		if failed {
+			cb.trip()
+			return
+		}
+
+		cb.probeSuccesses++
+		if cb.probeSuccesses >= cb.maxProbes {
+			cb.cooldown.Reset()
+			cb.setState(StateClosed)
+		}
+	}
+}
+
+func (cb *CircuitBreaker) shouldTrip() bool {
+	if cb.maxConsecutiveFailures > 0 &&
+		cb.consecutiveFailures >= cb.maxConsecutiveFailures {
+		return true
+	}
+
+	if cb.failureRate > 0 && cb.window != nil && cb.window.calls >= cb.minCalls {
+		rate := float64(cb.window.failures) / float64(cb.window.calls)
+		return rate >= cb.failureRate
+	}
+
+	return false
+}
+
+// trip opens the breaker for the next cool-down of the policy, or
+// for the last one once the policy is exhausted. The caller must
+// hold cb.mu.
+func (cb *CircuitBreaker) trip() {
+	delay, ok := cb.cooldown.Next()
+	if ok {
+		cb.lastCooldown = delay
+	}
+
+	cb.openUntil = cb.clock.Now().Add(cb.lastCooldown)
+	cb.setState(StateOpen)
+}
+
+// refreshState moves an open breaker to half-open once its cool-down
+// has passed, the caller must hold cb.mu.
+func (cb *CircuitBreaker) refreshState() {
+	if cb.state != StateOpen {
+		return
+	}
+
+	if cb.clock.Now().Before(cb.openUntil) {
+		return
+	}
+
+	cb.setState(StateHalfOpen)
+}
+
+// setState switches to state, resets the counters of the previous
+// one and notifies the subscribers, the caller must hold cb.mu.
+func (cb *CircuitBreaker) setState(state State) {
+	change := StateChange{
+		From: cb.state,
+		To:   state,
+		At:   cb.clock.Now(),
+	}
+
+	cb.state = state
+	cb.generation++
+
+	cb.consecutiveFailures = 0
+	cb.probes = 0
+	cb.probeSuccesses = 0
+
+	if cb.window != nil {
+		cb.window.reset()
+	}
+
+	cb.chsub.Notify(context.Background(), change)
+}
@@ -0,0 +1,191 @@
+package circuitbreaker
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mtchuikov/pkg/backoff"
+	"github.com/mtchuikov/pkg/backoff/backofftest"
+	"github.com/stretchr/testify/suite"
+)
+
+var errTest = errors.New("test error")
+
+type testCircuitBreaker struct {
+	suite.Suite
+	ctx   context.Context
+	clock *backofftest.Clock
+	cb    *CircuitBreaker
+}
+
+func TestCircuitBreakerSuite(t *testing.T) {
+	suite.Run(t, new(testCircuitBreaker))
+}
+
+func (s *testCircuitBreaker) SetupTest() {
+	s.ctx = context.Background()
+	s.clock = backofftest.NewClock(time.Unix(0, 0))
+	s.cb = New(
+		WithConsecutiveFailures(2),
+		WithCooldown(backoff.NewList(time.Second, 2*time.Second)),
+		WithClock(s.clock),
+	)
+}
+
+func (s *testCircuitBreaker) TearDownTest() {
+	s.cb.Close()
+}
+
+func (s *testCircuitBreaker) fail() error {
+	return s.cb.Execute(s.ctx, func(ctx context.Context) error {
+		return errTest
+	})
+}
+
+func (s *testCircuitBreaker) succeed() error {
+	return s.cb.Execute(s.ctx, func(ctx context.Context) error {
+		return nil
+	})
+}
+
+func (s *testCircuitBreaker) TestTrip() {
+	s.fail()
+	s.Require().Equal(StateClosed, s.cb.State(), "one failure must not trip the breaker")
+
+	s.fail()
+	s.Require().Equal(StateOpen, s.cb.State(), "two failures in a row must trip the breaker")
+
+	err := s.succeed()
+	s.Require().ErrorIs(err, ErrOpen, "open breaker must reject calls")
+}
+
+func (s *testCircuitBreaker) TestHalfOpen() {
+	s.fail()
+	s.fail()
+
+	s.clock.Advance(time.Second)
+	s.Require().Equal(StateHalfOpen, s.cb.State(), "breaker must be half-open after the cool-down")
+
+	done, err := s.cb.Allow()
+	s.Require().NoError(err, "half-open breaker must let a probe through")
+
+	_, err = s.cb.Allow()
+	s.Require().ErrorIs(err, ErrTooManyProbes, "half-open breaker must limit probes")
+
+	done(nil)
+	s.Require().Equal(StateClosed, s.cb.State(), "successful probe must close the breaker")
+}
+
+func (s *testCircuitBreaker) TestHalfOpenProbes() {
+	s.cb = New(
+		WithConsecutiveFailures(1),
+		WithCooldown(backoff.NewConstant(time.Second)),
+		WithHalfOpenProbes(2),
+		WithClock(s.clock),
+	)
+
+	s.fail()
+	s.clock.Advance(time.Second)
+
+	done, err := s.cb.Allow()
+	s.Require().NoError(err, "half-open breaker must let the first probe through")
+	done(nil)
+
+	done, err = s.cb.Allow()
+	s.Require().NoError(err, "half-open breaker must let the second probe through")
+
+	_, err = s.cb.Allow()
+	s.Require().ErrorIs(err, ErrTooManyProbes, "finished probes must count towards the limit")
+
+	done(errTest)
+	s.clock.Advance(time.Second)
+
+	var wg sync.WaitGroup
+	var allowed atomic.Int32
+	dones := make(chan func(error), 10)
+
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			done, err := s.cb.Allow()
+			if err == nil {
+				allowed.Add(1)
+				dones <- done
+			}
+		}()
+	}
+
+	wg.Wait()
+	close(dones)
+
+	s.Require().Equal(int32(2), allowed.Load(), "concurrent callers must not get more probes than the limit")
+
+	for done := range dones {
+		done(nil)
+	}
+
+	s.Require().Equal(StateClosed, s.cb.State(), "successful probes must close the breaker")
+}
+
+func (s *testCircuitBreaker) TestReopen() {
+	s.fail()
+	s.fail()
+
+	s.clock.Advance(time.Second)
+	s.fail()
+	s.Require().Equal(StateOpen, s.cb.State(), "failed probe must reopen the breaker")
+
+	s.clock.Advance(time.Second)
+	s.Require().Equal(StateOpen, s.cb.State(), "second cool-down must be longer")
+
+	s.clock.Advance(time.Second)
+	s.Require().Equal(StateHalfOpen, s.cb.State(), "breaker must be half-open after the second cool-down")
+}
+
+func (s *testCircuitBreaker) TestFailureRate() {
+	s.cb = New(
+		WithConsecutiveFailures(0),
+		WithFailureRate(0.5, 4, 4),
+		WithClock(s.clock),
+	)
+
+	s.fail()
+	s.succeed()
+	s.fail()
+	s.Require().Equal(StateClosed, s.cb.State(), "breaker must wait for min calls")
+
+	s.succeed()
+	s.Require().Equal(StateOpen, s.cb.State(), "breaker must trip when the failure rate is reached")
+}
+
+func (s *testCircuitBreaker) TestStaleResult() {
+	done, err := s.cb.Allow()
+	s.Require().NoError(err, "closed breaker must allow calls")
+
+	s.fail()
+	s.fail()
+
+	done(nil)
+	s.Require().Equal(StateOpen, s.cb.State(), "result of a call started before the trip must be ignored")
+}
+
+func (s *testCircuitBreaker) TestSubscribe() {
+	ch := s.cb.Subscribe().C()
+
+	s.fail()
+	s.fail()
+
+	select {
+	case change := <-ch:
+		s.Require().Equal(StateClosed, change.From, "change must start from closed")
+		s.Require().Equal(StateOpen, change.To, "change must end in open")
+	default:
+		s.Require().FailNow("subscriber must be notified about the trip")
+	}
+}
@@ -0,0 +1,62 @@
+package circuitbreaker
+
+import "github.com/mtchuikov/pkg/backoff"
+
+type Option func(*CircuitBreaker)
+
+// WithConsecutiveFailures trips the breaker after n failures in a
+// row, zero disables the threshold.
+func WithConsecutiveFailures(n int) Option {
+	return func(cb *CircuitBreaker) {
+		cb.maxConsecutiveFailures = n
+	}
+}
+
+// WithFailureRate trips the breaker once the share of failures among
+// the last windowSize calls reaches rate (from 0 to 1). The rate is
+// only evaluated after at least minCalls calls have been recorded in
+// the window. A zero rate disables the threshold.
+func WithFailureRate(rate float64, windowSize, minCalls int) Option {
+	return func(cb *CircuitBreaker) {
+		cb.failureRate = rate
+		cb.window = newWindow(windowSize)
+		cb.minCalls = minCalls
+	}
+}
+
+// WithCooldown sets the policy that computes how long the breaker
+// stays open after every trip. The policy is reset once the breaker
+// closes again, so repeated trips wait longer each time.
+func WithCooldown(policy backoff.Policy) Option {
+	return func(cb *CircuitBreaker) {
+		cb.cooldown = policy
+	}
+}
+
+// WithHalfOpenProbes sets the number of calls let through while the
+// breaker is half-open, whether they run one after another or at
+// once. The breaker closes once all of them succeed and opens again
+// on the first failure, the calls beyond them fail with
+// ErrTooManyProbes until then.
+func WithHalfOpenProbes(n int) Option {
+	return func(cb *CircuitBreaker) {
+		cb.maxProbes = max(n, 1)
+	}
+}
+
+// WithIsFailure sets the function that decides whether an error
+// returned by a call counts as a failure. By default every error
+// except context.Canceled does.
+func WithIsFailure(fn func(error) bool) Option {
+	return func(cb *CircuitBreaker) {
+		cb.isFailure = fn
+	}
+}
+
+// WithClock sets the clock used to measure the cool-down, it allows
+// to test the breaker with a fake clock.
+func WithClock(clock backoff.Clock) Option {
+	return func(cb *CircuitBreaker) {
+		cb.clock = clock
+	}
+}
@@ -2,25 +2,104 @@ package closer
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"slices"
 	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
 )
 
 const DefaultMaxConcurrent = 5
 
 var Global *Closer = nil
 
+var (
+	ErrPanic          = errors.New("task panicked")
+	ErrDuplicateTask  = errors.New("duplicate task name")
+	ErrDependencyLoop = errors.New("task dependency cycle")
+	ErrTaskTimeout    = errors.New("task timed out")
+)
+
+// Task is a function called by Close. The name identifies the task
+// in the errors and the report, tasks without a name are named after
+// their position.
 type Task struct {
+	Name string
 	Sync bool
-	Fn   func(context.Context)
+	Fn   func(context.Context) error
+
+	// DependsOn lists the names of the tasks that must be done before
+	// this one starts, for example a database pool depends on the
+	// servers that use it. Names of tasks that have not been added
+	// are ignored.
+	DependsOn []string
+
+	// Timeout limits how long Close waits for the task, zero uses the
+	// default set with WithTaskTimeout. A task past its timeout keeps
+	// running in the background, but the tasks that depend on it are
+	// started anyway.
+	Timeout time.Duration
+}
+
+// FromFunc adapts a task function that cannot fail.
+func FromFunc(fn func(context.Context)) func(context.Context) error {
+	return func(ctx context.Context) error {
+		fn(ctx)
+		return nil
+	}
+}
+
+// TaskError is the error of a failed task, Close returns one for
+// every failed task joined together. Stack is the stack of the
+// goroutine running a task that went past its timeout, it shows
+// where the task hangs even when no logger is set.
+type TaskError struct {
+	Name     string
+	Duration time.Duration
+	Err      error
+	Stack    string
+}
+
+func (e *TaskError) Error() string {
+	return fmt.Sprintf("task %q failed after %s: %v", e.Name, e.Duration, e.Err)
+}
+
+func (e *TaskError) Unwrap() error {
+	return e.Err
+}
+
+// Report tells how the tasks ended during Close. Tasks that were
+// still running or had not started yet when the context of Close was
+// done are reported as timed out.
+type Report struct {
+	Finished []string
+	Failed   []string
+	TimedOut []string
 }
 
+type taskStatus int
+
+const (
+	taskTimedOut taskStatus = iota
+	taskFinished
+	taskFailed
+)
+
 type Closer struct {
 	mu            sync.Mutex
 	tasks         []Task
 	numTasks      int
 	closeOnce     sync.Once
 	maxConcurrent int
+	taskTimeout   time.Duration
+	log           zerolog.Logger
+
+	// report and err are the results of the first Close, returned
+	// by the later ones.
+	report Report
+	err    error
 }
 
 func new(opts ...Option) *Closer {
@@ -29,10 +108,12 @@ func new(opts ...Option) *Closer {
 		tasks:         make([]Task, 0, 3),
 		closeOnce:     sync.Once{},
 		maxConcurrent: DefaultMaxConcurrent,
+		taskTimeout:   0,
+		log:           zerolog.Nop(),
 	}
 
 	for _, opt := range opts {
-		opt(Global)
+		opt(c)
 	}
 
 	return c
@@ -50,17 +131,35 @@ func (c *Closer) NumTasks() int {
 	return c.numTasks
 }
 
-func (c *Closer) Add(task Task) {
+// Add appends task to the closer. It fails if another task has the
+// same name or if the dependencies of task form a cycle.
+func (c *Closer) Add(task Task) error {
 	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	err := c.validate(task)
+	if err != nil {
+		return err
+	}
+
 	c.numTasks++
 	c.tasks = append(c.tasks, task)
-	c.mu.Unlock()
+
+	return nil
 }
 
-func (c *Closer) AddWithPriority(priority int, task Task) {
+// AddWithPriority is like Add, but inserts task at priority. Among
+// the tasks whose dependencies are done, the ones with a lower
+// priority start first.
+func (c *Closer) AddWithPriority(priority int, task Task) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
+	err := c.validate(task)
+	if err != nil {
+		return err
+	}
+
 	c.numTasks++
 
 	if priority <= 0 {
@@ -69,10 +168,55 @@ func (c *Closer) AddWithPriority(priority int, task Task) {
 
 	if priority >= c.numTasks {
 		c.tasks = append(c.tasks, task)
-		return
+		return nil
 	}
 
 	c.tasks = slices.Insert(c.tasks, priority, task)
+
+	return nil
+}
+
+// validate checks that task can be added, the caller must hold c.mu.
+func (c *Closer) validate(task Task) error {
+	if task.Name == "" {
+		return nil
+	}
+
+	deps := make(map[string][]string, len(c.tasks))
+	for _, t := range c.tasks {
+		if t.Name == task.Name {
+			return fmt.Errorf("%w: %q", ErrDuplicateTask, task.Name)
+		}
+
+		if t.Name != "" {
+			deps[t.Name] = t.DependsOn
+		}
+	}
+
+	// The tasks already added have no cycles, so a new one must go
+	// through task.
+	visited := make(map[string]bool)
+
+	var leadsToTask func(name string) bool
+	leadsToTask = func(name string) bool {
+		if name == task.Name {
+			return true
+		}
+
+		if visited[name] {
+			return false
+		}
+
+		visited[name] = true
+
+		return slices.ContainsFunc(deps[name], leadsToTask)
+	}
+
+	if slices.ContainsFunc(task.DependsOn, leadsToTask) {
+		return fmt.Errorf("%w: %q", ErrDependencyLoop, task.Name)
+	}
+
+	return nil
 }
 
 func (c *Closer) Reset() {
@@ -80,58 +224,263 @@ func (c *Closer) Reset() {
 	c.tasks = make([]Task, 0, 3)
 	c.numTasks = 0
 	c.closeOnce = sync.Once{}
+	c.report = Report{}
+	c.err = nil
 	c.mu.Unlock()
 }
 
 func (c *Closer) Close(ctx context.Context) error {
-	var err error
+	_, err := c.CloseWithReport(ctx)
+	return err
+}
+
+// CloseWithReport is like Close, but also reports how every task
+// ended. Later calls return the results of the first one.
+func (c *Closer) CloseWithReport(ctx context.Context) (Report, error) {
 	closeFn := func() {
-		sem := make(chan struct{}, c.maxConcurrent)
-		var wg sync.WaitGroup
+		// The tasks past their timeout keep running after Close
+		// returns, so they work on a copy that Add and Reset do not
+		// touch.
+		c.mu.Lock()
+		tasks := slices.Clone(c.tasks)
+		c.mu.Unlock()
+
+		statuses := make([]taskStatus, len(tasks))
+		var errs []error
 
-		for _, task := range c.tasks {
-			select {
-			case <-ctx.Done():
-				err = ctx.Err()
+		record := func(idx int, err error) {
+			switch {
+			case err == nil:
+				statuses[idx] = taskFinished
 				return
-			case sem <- struct{}{}:
+			case errors.Is(err, ErrTaskTimeout):
+				statuses[idx] = taskTimedOut
+			default:
+				statuses[idx] = taskFailed
+			}
+
+			errs = append(errs, err)
+		}
+
+		err := c.runTasks(ctx, tasks, record)
+
+		c.report = newReport(tasks, statuses)
+		c.err = errors.Join(append([]error{err}, errs...)...)
+	}
+
+	c.closeOnce.Do(closeFn)
+
+	return c.report, c.err
+}
+
+// taskName returns the name of task, or its position idx when it has
+// none.
+func taskName(idx int, task Task) string {
+	name := task.Name
+	if name == "" {
+		name = fmt.Sprintf("task %d", idx)
+	}
+
+	return name
+}
+
+func newReport(tasks []Task, statuses []taskStatus) Report {
+	var report Report
+	for idx, status := range statuses {
+		name := taskName(idx, tasks[idx])
+
+		switch status {
+		case taskFinished:
+			report.Finished = append(report.Finished, name)
+		case taskFailed:
+			report.Failed = append(report.Failed, name)
+		default:
+			report.TimedOut = append(report.TimedOut, name)
+		}
+	}
+
+	return report
+}
+
+// runTask calls task, found at idx, within its timeout. When the
+// task goes past it, the stack of the goroutine running the task is
+// logged and attached to the returned TaskError to show where it
+// hangs.
+func (c *Closer) runTask(ctx context.Context, idx int, task Task) error {
+	timeout := task.Timeout
+	if timeout <= 0 {
+		timeout = c.taskTimeout
+	}
+
+	name := taskName(idx, task)
+
+	if timeout <= 0 {
+		return callTask(ctx, name, task)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	goid := make(chan uint64, 1)
+	result := make(chan error, 1)
+
+	go func() {
+		goid <- goroutineID()
+		result <- callTask(ctx, name, task)
+	}()
+
+	id := <-goid
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case err := <-result:
+		return err
+	case <-timer.C:
+	}
+
+	stack := goroutineStack(id)
+
+	c.log.Error().
+		Str("task", name).
+		Dur("timeout", timeout).
+		Str("stack", stack).
+		Msg("shutdown task timed out")
+
+	return &TaskError{
+		Name:     name,
+		Duration: timeout,
+		Err:      ErrTaskTimeout,
+		Stack:    stack,
+	}
+}
+
+// callTask calls task and turns its error or panic into a TaskError
+// named name.
+func callTask(ctx context.Context, name string, task Task) (err error) {
+	start := time.Now()
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%w: %v", ErrPanic, r)
+		}
+
+		if err != nil {
+			err = &TaskError{
+				Name:     name,
+				Duration: time.Since(start),
+				Err:      err,
 			}
+		}
+	}()
 
-			wg.Add(1)
-			doneFn := func() {
-				wg.Done()
-				<-sem
+	return task.Fn(ctx)
+}
+
+// dependents returns, for every task, the tasks that depend on it and
+// the number of tasks it depends on.
+func dependents(tasks []Task) ([][]int, []int) {
+	byName := make(map[string]int, len(tasks))
+	for idx, task := range tasks {
+		if task.Name != "" {
+			byName[task.Name] = idx
+		}
+	}
+
+	dependents := make([][]int, len(tasks))
+	pending := make([]int, len(tasks))
+
+	for idx, task := range tasks {
+		seen := make(map[int]bool, len(task.DependsOn))
+		for _, name := range task.DependsOn {
+			dep, ok := byName[name]
+			if !ok || seen[dep] {
+				continue
 			}
 
-			if task.Sync {
-				task.Fn(ctx)
-				doneFn()
+			seen[dep] = true
+			dependents[dep] = append(dependents[dep], idx)
+			pending[idx]++
+		}
+	}
+
+	return dependents, pending
+}
+
+// runTasks starts every task once the tasks it depends on are done,
+// keeping at most maxConcurrent of them running. It calls record from
+// its own goroutine as the tasks end, and stops waiting for them once
+// ctx is done.
+func (c *Closer) runTasks(ctx context.Context, tasks []Task, record func(idx int, err error)) error {
+	dependents, pending := dependents(tasks)
+
+	var ready []int
+	for idx := range tasks {
+		if pending[idx] == 0 {
+			ready = append(ready, idx)
+		}
+	}
+
+	type result struct {
+		idx int
+		err error
+	}
+
+	// The channel has room for every task, so the tasks still
+	// running when ctx is done never block on it.
+	results := make(chan result, len(tasks))
+	maxConcurrent := max(c.maxConcurrent, 1)
+	running := 0
+
+	finish := func(idx int, err error) {
+		record(idx, err)
+
+		for _, dep := range dependents[idx] {
+			pending[dep]--
+			if pending[dep] == 0 {
+				pos, _ := slices.BinarySearch(ready, dep)
+				ready = slices.Insert(ready, pos, dep)
+			}
+		}
+	}
+
+	for done := 0; done < len(tasks); {
+		for len(ready) > 0 && running < maxConcurrent {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+
+			idx := ready[0]
+			ready = ready[1:]
+
+			if tasks[idx].Sync {
+				finish(idx, c.runTask(ctx, idx, tasks[idx]))
+				done++
 				continue
 			}
 
+			running++
 			go func() {
-				task.Fn(ctx)
-				doneFn()
+				results <- result{idx: idx, err: c.runTask(ctx, idx, tasks[idx])}
 			}()
 		}
 
-		close(sem)
-
-		waitTillDone := make(chan struct{})
-		go func() {
-			wg.Wait()
-			close(waitTillDone)
-		}()
+		// Only a dependency cycle could leave tasks that never get
+		// ready, and Add rejects those.
+		if running == 0 {
+			return nil
+		}
 
 		select {
 		case <-ctx.Done():
-			err = ctx.Err()
-			return
-		case <-waitTillDone:
+			return ctx.Err()
+		case r := <-results:
+			running--
+			done++
+			finish(r.idx, r.err)
 		}
 	}
 
-	c.closeOnce.Do(closeFn)
-
-	return err
+	return nil
 }
@@ -0,0 +1,205 @@
+package closer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/suite"
+)
+
+type testCloser struct {
+	suite.Suite
+	ctx    context.Context
+	closer *Closer
+}
+
+func TestCloserSuite(t *testing.T) {
+	suite.Run(t, &testCloser{})
+}
+
+func (s *testCloser) SetupTest() {
+	s.ctx = context.Background()
+	s.closer = New(WithMaxConcurrent(2))
+}
+
+func (s *testCloser) TestOptions() {
+	errMsg := "options must be applied to the new closer"
+	s.Require().Equal(2, s.closer.maxConcurrent, errMsg)
+}
+
+func (s *testCloser) TestCloseWithReport() {
+	s.closer.Add(Task{Sync: true, Fn: FromFunc(func(ctx context.Context) {})})
+	s.closer.Add(Task{Fn: FromFunc(func(ctx context.Context) {})})
+
+	report, err := s.closer.CloseWithReport(s.ctx)
+	s.Require().NoError(err, "close must succeed")
+	s.Require().Len(report.Finished, 2, "all the tasks must finish")
+	s.Require().Empty(report.TimedOut, "no task must time out")
+}
+
+func (s *testCloser) TestCloseTimeout() {
+	block := make(chan struct{})
+	defer close(block)
+
+	s.closer.Add(Task{Fn: FromFunc(func(ctx context.Context) {})})
+	s.closer.Add(Task{Fn: func(ctx context.Context) error {
+		<-block
+		return nil
+	}})
+
+	ctx, cancel := context.WithTimeout(s.ctx, 10*time.Millisecond)
+	defer cancel()
+
+	report, err := s.closer.CloseWithReport(ctx)
+	s.Require().ErrorIs(err, context.DeadlineExceeded, "close must end with its context")
+	s.Require().Equal([]string{"task 1"}, report.TimedOut, "hung task must be reported as timed out")
+}
+
+func (s *testCloser) TestTaskErrors() {
+	errTest := errors.New("test error")
+
+	s.closer.Add(Task{Name: "ok", Fn: FromFunc(func(ctx context.Context) {})})
+	s.closer.Add(Task{Name: "fail", Fn: func(ctx context.Context) error {
+		return errTest
+	}})
+	s.closer.Add(Task{Name: "panic", Fn: func(ctx context.Context) error {
+		panic("boom")
+	}})
+
+	report, err := s.closer.CloseWithReport(s.ctx)
+	s.Require().ErrorIs(err, errTest, "task error must be returned")
+	s.Require().ErrorIs(err, ErrPanic, "task panic must be turned into an error")
+
+	var taskErr *TaskError
+	s.Require().ErrorAs(err, &taskErr, "task error must carry the task")
+	s.Require().Contains([]string{"fail", "panic"}, taskErr.Name, "task error must be named after the task")
+
+	s.Require().Equal([]string{"ok"}, report.Finished, "successful task must be reported as finished")
+	s.Require().Equal([]string{"fail", "panic"}, report.Failed, "failed tasks must be reported as failed")
+}
+
+func (s *testCloser) TestDependsOn() {
+	var mu sync.Mutex
+	var order []string
+
+	task := func(name string, deps ...string) Task {
+		return Task{
+			Name:      name,
+			DependsOn: deps,
+			Fn: FromFunc(func(ctx context.Context) {
+				mu.Lock()
+				order = append(order, name)
+				mu.Unlock()
+			}),
+		}
+	}
+
+	s.Require().NoError(s.closer.Add(task("db", "http-server", "workers")), "task must be added")
+	s.Require().NoError(s.closer.Add(task("http-server")), "task must be added")
+	s.Require().NoError(s.closer.Add(task("workers", "http-server", "unknown")), "task must be added")
+
+	err := s.closer.Close(s.ctx)
+	s.Require().NoError(err, "close must succeed")
+	s.Require().Equal([]string{"http-server", "workers", "db"}, order, "tasks must run after their dependencies")
+}
+
+func (s *testCloser) TestAddInvalid() {
+	noop := FromFunc(func(ctx context.Context) {})
+
+	s.Require().NoError(s.closer.Add(Task{Name: "a", DependsOn: []string{"b"}, Fn: noop}), "task must be added")
+	s.Require().NoError(s.closer.Add(Task{Name: "b", DependsOn: []string{"c"}, Fn: noop}), "task must be added")
+
+	err := s.closer.Add(Task{Name: "c", DependsOn: []string{"a"}, Fn: noop})
+	s.Require().ErrorIs(err, ErrDependencyLoop, "cycle must be rejected")
+
+	err = s.closer.Add(Task{Name: "a", Fn: noop})
+	s.Require().ErrorIs(err, ErrDuplicateTask, "duplicate name must be rejected")
+
+	s.Require().Equal(2, s.closer.NumTasks(), "rejected tasks must not be added")
+}
+
+func (s *testCloser) TestTaskTimeout() {
+	var buf bytes.Buffer
+	s.closer = New(WithLogger(zerolog.New(&buf)), WithTaskTimeout(time.Hour))
+
+	block := make(chan struct{})
+	defer close(block)
+
+	hung := func(ctx context.Context) {
+		<-block
+	}
+
+	ran := false
+	s.Require().NoError(s.closer.Add(Task{
+		Name:    "hung",
+		Timeout: 10 * time.Millisecond,
+		Fn:      FromFunc(hung),
+	}), "task must be added")
+	s.Require().NoError(s.closer.Add(Task{
+		Name:      "next",
+		DependsOn: []string{"hung"},
+		Fn: FromFunc(func(ctx context.Context) {
+			ran = true
+		}),
+	}), "task must be added")
+
+	report, err := s.closer.CloseWithReport(s.ctx)
+	s.Require().ErrorIs(err, ErrTaskTimeout, "timeout of the task must be returned")
+	s.Require().Equal([]string{"hung"}, report.TimedOut, "hung task must be reported as timed out")
+	s.Require().True(ran, "tasks after the hung one must still run")
+
+	log := buf.String()
+	s.Require().Contains(log, `"task":"hung"`, "log must name the hung task")
+	s.Require().Contains(log, "TestTaskTimeout", "log must contain the stack of the hung task")
+}
+
+func (s *testCloser) TestTaskTimeoutStack() {
+	block := make(chan struct{})
+	defer close(block)
+
+	s.Require().NoError(s.closer.Add(Task{
+		Name:    "hung",
+		Timeout: 10 * time.Millisecond,
+		Fn: FromFunc(func(ctx context.Context) {
+			<-block
+		}),
+	}), "task must be added")
+
+	err := s.closer.Close(s.ctx)
+	s.Require().ErrorIs(err, ErrTaskTimeout, "timeout of the task must be returned")
+
+	var taskErr *TaskError
+	s.Require().ErrorAs(err, &taskErr, "error must be a TaskError")
+	s.Require().Equal("hung", taskErr.Name, "error must name the hung task")
+	s.Require().Contains(taskErr.Stack, "TestTaskTimeoutStack", "error must contain the stack of the hung task")
+}
+
+func (s *testCloser) TestResetAfterTimeout() {
+	release := make(chan struct{})
+	returned := make(chan struct{})
+
+	s.Require().NoError(s.closer.Add(Task{
+		Name:    "hung",
+		Timeout: 10 * time.Millisecond,
+		Fn: func(ctx context.Context) error {
+			defer close(returned)
+			<-release
+			return ctx.Err()
+		},
+	}), "task must be added")
+
+	err := s.closer.Close(s.ctx)
+	s.Require().ErrorIs(err, ErrTaskTimeout, "timeout of the task must be returned")
+
+	s.closer.Reset()
+	close(release)
+	<-returned
+
+	s.Require().NoError(s.closer.Add(Task{Name: "hung", Fn: FromFunc(func(ctx context.Context) {})}), "task must be added after reset")
+	s.Require().NoError(s.closer.Close(s.ctx), "closer must be usable after reset")
+}
@@ -1,5 +1,11 @@
 package closer
 
+import (
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
 type Option func(*Closer)
 
 func WithMaxConcurrent(max int) Option {
@@ -7,3 +13,19 @@ func WithMaxConcurrent(max int) Option {
 		c.maxConcurrent = max
 	}
 }
+
+// WithTaskTimeout sets the timeout of the tasks that do not have
+// their own, zero means no timeout.
+func WithTaskTimeout(timeout time.Duration) Option {
+	return func(c *Closer) {
+		c.taskTimeout = timeout
+	}
+}
+
+// WithLogger sets the logger used to report the tasks that go past
+// their timeout.
+func WithLogger(log zerolog.Logger) Option {
+	return func(c *Closer) {
+		c.log = log
+	}
+}
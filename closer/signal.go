@@ -0,0 +1,58 @@
+package closer
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// exit is called when a second signal arrives during the shutdown,
+// it is replaced in tests.
+var exit = os.Exit
+
+// WaitForSignal blocks until one of signals arrives or ctx is done,
+// then closes c within timeout. Without signals it waits for SIGINT
+// and SIGTERM, and a zero timeout lets Close run until the tasks are
+// done. A second signal during the shutdown exits the process with
+// status 1 right away.
+func (c *Closer) WaitForSignal(ctx context.Context, timeout time.Duration, signals ...os.Signal) (Report, error) {
+	if len(signals) == 0 {
+		signals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+	}
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, signals...)
+	defer signal.Stop(sigCh)
+
+	select {
+	case <-ctx.Done():
+	case <-sigCh:
+	}
+
+	closeCtx := context.Background()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		closeCtx, cancel = context.WithTimeout(closeCtx, timeout)
+		defer cancel()
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+
+	go func() {
+		select {
+		case <-sigCh:
+			exit(1)
+		case <-done:
+		}
+	}()
+
+	return c.CloseWithReport(closeCtx)
+}
+
+// WaitForSignal calls WaitForSignal on the Global closer.
+func WaitForSignal(ctx context.Context, timeout time.Duration, signals ...os.Signal) (Report, error) {
+	return Global.WaitForSignal(ctx, timeout, signals...)
+}
@@ -0,0 +1,63 @@
+//go:build unix
+
+package closer
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// signalUntil sends sig to the test process every millisecond until
+// done is closed.
+func signalUntil(sig syscall.Signal, done <-chan struct{}) {
+	ticker := time.NewTicker(time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		syscall.Kill(os.Getpid(), sig)
+
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *testCloser) TestWaitForSignal() {
+	// Catch SIGUSR1 for the whole test, so that a signal sent before
+	// WaitForSignal is listening does not kill the test binary.
+	caught := make(chan os.Signal, 1)
+	signal.Notify(caught, syscall.SIGUSR1)
+	defer signal.Stop(caught)
+
+	exited := make(chan struct{})
+	var code int
+	exit = func(c int) {
+		code = c
+		close(exited)
+	}
+	defer func() {
+		exit = os.Exit
+	}()
+
+	started := make(chan struct{})
+
+	s.closer.Add(Task{Fn: FromFunc(func(ctx context.Context) {
+		close(started)
+		<-exited
+	})})
+
+	go func() {
+		signalUntil(syscall.SIGUSR1, started)
+		signalUntil(syscall.SIGUSR1, exited)
+	}()
+
+	report, err := s.closer.WaitForSignal(s.ctx, time.Second, syscall.SIGUSR1)
+	s.Require().NoError(err, "shutdown must succeed")
+	s.Require().Len(report.Finished, 1, "task must finish")
+	s.Require().Equal(1, code, "second signal must exit with status 1")
+}
@@ -0,0 +1,44 @@
+package closer
+
+import (
+	"bytes"
+	"fmt"
+	"runtime"
+	"strconv"
+)
+
+// goroutineID returns the ID of the calling goroutine, parsed from the
+// "goroutine N [running]:" header of its stack.
+func goroutineID() uint64 {
+	var buf [64]byte
+	n := runtime.Stack(buf[:], false)
+
+	field := bytes.Fields(buf[:n])[1]
+	id, _ := strconv.ParseUint(string(field), 10, 64)
+
+	return id
+}
+
+// goroutineStack returns the stack of the goroutine with id, or an
+// empty string if it has exited.
+func goroutineStack(id uint64) string {
+	buf := make([]byte, 64<<10)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			buf = buf[:n]
+			break
+		}
+
+		buf = make([]byte, 2*len(buf))
+	}
+
+	header := []byte(fmt.Sprintf("goroutine %d [", id))
+	for stack := range bytes.SplitSeq(buf, []byte("\n\n")) {
+		if bytes.HasPrefix(stack, header) {
+			return string(stack)
+		}
+	}
+
+	return ""
+}
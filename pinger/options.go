@@ -1,23 +1,70 @@
 package pinger
 
-import "time"
+import (
+	"time"
+
+	"github.com/mtchuikov/pkg/backoff"
+)
 
 type Option func(*Pinger)
 
 func WithPingInterval(interval time.Duration) Option {
 	return func(p *Pinger) {
-		p.ticker = time.NewTicker(interval)
+		p.interval = interval
+	}
+}
+
+// WithClock sets the clock used to schedule pings, it allows to
+// test the pinger with a fake clock instead of real sleeps.
+func WithClock(clock backoff.Clock) Option {
+	return func(p *Pinger) {
+		p.clock = clock
+	}
+}
+
+// WithBackoff sets the policy used to delay the next ping after a
+// failed one. Once the policy is exhausted the pinger falls back to
+// the ping interval until the next successful ping resets it.
+func WithBackoff(policy backoff.Policy) Option {
+	return func(p *Pinger) {
+		p.backoff = policy
 	}
 }
 
+// WithBackoffInterval sets the initial backoff interval, it only
+// applies to a *backoff.Backoff policy, whatever the order of the
+// options is.
 func WithBackoffInterval(interval time.Duration) Option {
 	return func(p *Pinger) {
-		p.backoff.Interval = interval
+		p.backoffInterval = interval
 	}
 }
 
+// WithBackoffMax sets the maximum backoff interval, it only applies
+// to a *backoff.Backoff policy, whatever the order of the options is.
 func WithBackoffMax(interval time.Duration) Option {
 	return func(p *Pinger) {
-		p.backoff.Max = interval
+		p.backoffMax = interval
+	}
+}
+
+// WithBudget makes every ping that follows a failed one withdraw a
+// token from budget, and every successful ping deposit the ratio of
+// the budget back. While the budget is empty such pings are skipped,
+// which keeps many pingers sharing the budget from hammering a
+// dependency that is down.
+func WithBudget(budget *backoff.Budget) Option {
+	return func(p *Pinger) {
+		p.budget = budget
+	}
+}
+
+// WithHooks sets the hooks notified about failed pings and about the
+// first successful ping after a failure, by default the Hooks of a
+// *backoff.Backoff policy are used. The pinger never gives up, so
+// OnGiveUp is not called.
+func WithHooks(hooks backoff.Hooks) Option {
+	return func(p *Pinger) {
+		p.hooks = &hooks
 	}
 }
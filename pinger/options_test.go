@@ -0,0 +1,51 @@
+package pinger
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mtchuikov/pkg/backoff"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/suite"
+)
+
+type nopPinger struct{}
+
+func (nopPinger) Ping(ctx context.Context) error {
+	return nil
+}
+
+type testOptions struct {
+	suite.Suite
+}
+
+func TestOptionsSuite(t *testing.T) {
+	suite.Run(t, new(testOptions))
+}
+
+func (s *testOptions) TestBackoffOptionsOrder() {
+	policy := backoff.New()
+
+	p := New(zerolog.Nop(), nopPinger{},
+		WithBackoffInterval(time.Second),
+		WithBackoffMax(time.Minute),
+		WithBackoff(policy),
+	)
+	defer p.Close(context.Background())
+
+	errMsg := "backoff options must apply to a policy set after them"
+	s.Require().Equal(time.Second, policy.Interval, errMsg)
+	s.Require().Equal(time.Minute, policy.Max, errMsg)
+
+	linear := backoff.NewLinear(time.Second, time.Second, 2*time.Second)
+
+	p = New(zerolog.Nop(), nopPinger{},
+		WithBackoff(linear),
+		WithBackoffInterval(time.Minute),
+	)
+	defer p.Close(context.Background())
+
+	errMsg = "backoff options must leave other policies untouched"
+	s.Require().Equal(time.Second, linear.Interval, errMsg)
+}
@@ -21,13 +21,24 @@ type pinger interface {
 }
 
 type Pinger struct {
-	log       zerolog.Logger
-	mu        sync.Mutex
-	pinger    pinger
-	ticker    *time.Ticker
-	backoff   *backoff.Backoff
+	log      zerolog.Logger
+	mu       sync.Mutex
+	pinger   pinger
+	interval time.Duration
+	clock    backoff.Clock
+	backoff  backoff.Policy
+
+	// backoffInterval and backoffMax are set by the options of the
+	// same name and applied once all the options have run, zero
+	// keeps the value of the policy.
+	backoffInterval time.Duration
+	backoffMax      time.Duration
+
+	budget    *backoff.Budget
+	hooks     *backoff.Hooks
 	err       error
 	chsub     *chsubscription.ChSubscription[error]
+	done      chan struct{}
 	closeOnce sync.Once
 }
 
@@ -36,36 +47,76 @@ func New(log zerolog.Logger, pinger pinger, opts ...Option) *Pinger {
 		log:       log,
 		mu:        sync.Mutex{},
 		pinger:    pinger,
-		ticker:    time.NewTicker(DefaultPingInterval),
+		interval:  DefaultPingInterval,
+		clock:     backoff.SystemClock,
+		budget:    nil,
+		hooks:     nil,
 		err:       nil,
-		chsub:     chsubscription.New[error](),
+		chsub:     chsubscription.New[error](chsubscription.WithLastValue()),
+		done:      make(chan struct{}),
 		closeOnce: sync.Once{},
 	}
 
-	backoff := backoff.New()
-	backoff.Interval = DefaultBackoffInterval
-	backoff.Max = DefaultBackoffMax
+	defaultBackoff := backoff.New()
+	defaultBackoff.Interval = DefaultBackoffInterval
+	defaultBackoff.Max = DefaultBackoffMax
 
-	p.backoff = backoff
+	p.backoff = defaultBackoff
 
 	for _, opt := range opts {
 		opt(p)
 	}
 
+	b, ok := p.backoff.(*backoff.Backoff)
+	if ok && p.backoffInterval > 0 {
+		b.Interval = p.backoffInterval
+	}
+
+	if ok && p.backoffMax > 0 {
+		b.Max = p.backoffMax
+	}
+
 	return p
 }
 
 func (p *Pinger) Ping(ctx context.Context, timeout time.Duration) {
+	timer := p.clock.NewTimer(p.interval)
+	defer timer.Stop()
+
+	hooks := p.hooks
+	b, ok := p.backoff.(*backoff.Backoff)
+	if hooks == nil && ok {
+		hooks = &b.Hooks
+	}
+
+	// failures counts the pings failed in a row, while it is not zero
+	// every ping is a retry and is paid from the budget.
+	failures := 0
+	var firstFailure time.Time
+
 	for {
 		select {
 		case <-ctx.Done():
 			p.log.Debug().
 				Msg("context cancelled, stopping ping loop")
 			return
-		case <-p.ticker.C:
+		case <-p.done:
+			p.log.Debug().
+				Msg("pinger closed, stopping ping loop")
+			return
+		case <-timer.C():
 			p.log.Debug().
 				Msg("ticker ticked, starting ping")
 
+			if failures > 0 && p.budget != nil && !p.budget.Withdraw() {
+				p.log.Debug().
+					Err(backoff.ErrBudgetExhausted).
+					Msg("skipping ping")
+
+				timer.Reset(p.nextDelay(nil))
+				continue
+			}
+
 			timeoutCtx, cancel := context.WithTimeout(ctx, timeout)
 
 			p.mu.Lock()
@@ -82,22 +133,54 @@ func (p *Pinger) Ping(ctx context.Context, timeout time.Duration) {
 				p.err = err
 				p.chsub.Notify(ctx, err)
 
-				delay := p.backoff.Next()
-				select {
-				case <-ctx.Done():
-					return
-				case <-time.After(delay):
-					continue
+				failures++
+				if failures == 1 {
+					firstFailure = p.clock.Now()
 				}
+
+				delay := p.nextDelay(err)
+				hooks.NotifyRetry(failures, delay, err)
+
+				timer.Reset(delay)
+				continue
 			}
 
 			p.log.Debug().
 				Msg("ping successful")
 			p.backoff.Reset()
+
+			if p.budget != nil {
+				p.budget.Deposit()
+			}
+
+			if failures > 0 {
+				elapsed := p.clock.Now().Sub(firstFailure)
+				hooks.NotifySuccess(failures+1, elapsed)
+
+				// The failure is over, subscribers that come later
+				// must not learn about it.
+				p.chsub.ResetReplay()
+			}
+
+			failures = 0
+
+			timer.Reset(p.interval)
 		}
 	}
 }
 
+// nextDelay returns the delay before the ping that follows a failed
+// one, it is never shorter than the ping interval. A delay requested
+// by the server through err takes precedence over the backoff.
+func (p *Pinger) nextDelay(err error) time.Duration {
+	delay, ok := backoff.NextDelay(p.backoff, err, p.clock)
+	if !ok {
+		delay = 0
+	}
+
+	return max(delay, p.interval)
+}
+
 func (p *Pinger) ChangePinger(pinger pinger) {
 	p.mu.Lock()
 	p.pinger = pinger
@@ -108,17 +191,45 @@ func (p *Pinger) Error() error {
 	return p.err
 }
 
-func (p *Pinger) Subscribe() <-chan error {
+// Subscribe returns a subscription to the ping errors. While the
+// pinger is failing, a new subscription first receives the last
+// error.
+func (p *Pinger) Subscribe() *chsubscription.Subscription[error] {
 	return p.chsub.Subscribe(3)
 }
 
-func (p *Pinger) Unsubscribe(item <-chan error) {
-	p.chsub.Unsubscribe(item)
+// SubscribeFunc is like Subscribe, but only delivers the errors for
+// which filter returns true.
+func (p *Pinger) SubscribeFunc(filter func(error) bool) *chsubscription.Subscription[error] {
+	return p.chsub.SubscribeFunc(3, filter)
+}
+
+// SubscribeDebounce is like Subscribe, but only delivers the last
+// error of a burst once no other error has occurred for quiet.
+func (p *Pinger) SubscribeDebounce(quiet time.Duration) *chsubscription.Subscription[error] {
+	return chsubscription.Debounce(p.chsub, 3, quiet)
+}
+
+// SubscribeThrottle is like Subscribe, but delivers at most one error
+// per interval.
+func (p *Pinger) SubscribeThrottle(interval time.Duration) *chsubscription.Subscription[error] {
+	return chsubscription.Throttle(p.chsub, 3, interval)
+}
+
+// SubscribeBatch is like Subscribe, but delivers the errors in
+// batches of size, or fewer once wait has passed since the first
+// error of the batch.
+func (p *Pinger) SubscribeBatch(size int, wait time.Duration) *chsubscription.Subscription[[]error] {
+	return chsubscription.Batch(p.chsub, 3, size, wait)
+}
+
+func (p *Pinger) Unsubscribe(sub *chsubscription.Subscription[error]) {
+	p.chsub.Unsubscribe(sub)
 }
 
 func (p *Pinger) Close(ctx context.Context) error {
 	closeFn := func() {
-		p.ticker.Stop()
+		close(p.done)
 		p.chsub.Close()
 	}
 